@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/bcneng/candebot/internal/audit"
+	"github.com/bcneng/candebot/internal/jobs"
 	"github.com/bcneng/candebot/slackx"
 	"github.com/slack-go/slack"
 )
@@ -29,8 +32,7 @@ func apiCreateChannelHandler(botCtx Context) http.HandlerFunc {
 			return
 		}
 
-		token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
-		if !found || subtle.ConstantTimeCompare([]byte(token), []byte(botCtx.Config.APIKey)) != 1 {
+		if !apiAuthorized(botCtx, r) {
 			http.Error(w, "unauthorized", http.StatusUnauthorized)
 			return
 		}
@@ -50,6 +52,13 @@ func apiCreateChannelHandler(botCtx Context) http.HandlerFunc {
 			ChannelName: req.Name,
 			IsPrivate:   false,
 		})
+		Audit(botCtx, audit.Entry{
+			Actor:    audit.ActorAPI,
+			Action:   "create_channel",
+			Target:   "#" + req.Name,
+			Params:   map[string]string{"description": req.Description, "pr_link": req.PRLink},
+			Severity: audit.SeverityHigh,
+		}, err)
 		if err != nil {
 			log.Printf("[ERROR] Failed to create channel %q: %s", req.Name, err)
 			http.Error(w, fmt.Sprintf("failed to create channel: %s", err), http.StatusInternalServerError)
@@ -72,3 +81,48 @@ func apiCreateChannelHandler(botCtx Context) http.HandlerFunc {
 		_ = json.NewEncoder(w).Encode(createChannelResponse{ChannelID: channel.ID})
 	}
 }
+
+// apiJobsStatsHandler returns the job board stats, optionally filtered by the year passed as query param.
+func apiJobsStatsHandler(botCtx Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		if !apiAuthorized(botCtx, r) {
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		var year int
+		if y := r.URL.Query().Get("year"); y != "" {
+			var err error
+			if year, err = strconv.Atoi(y); err != nil || year <= 0 {
+				http.Error(w, "invalid year", http.StatusBadRequest)
+				return
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(JobsStats(botCtx, year))
+	}
+}
+
+// JobsStats computes the stats of the job posts published in the given year, or all of them if year is zero.
+func JobsStats(botCtx Context, year int) jobs.Stats {
+	var posts []jobs.Post
+	if botCtx.JobPosts != nil {
+		posts = botCtx.JobPosts.Posts()
+	}
+
+	return jobs.ComputeStats(posts, year, jobsStatsTopCompanies)
+}
+
+// jobsStatsTopCompanies is the number of companies listed in the job board stats.
+const jobsStatsTopCompanies = 10
+
+func apiAuthorized(botCtx Context, r *http.Request) bool {
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return found && subtle.ConstantTimeCompare([]byte(token), []byte(botCtx.Config.APIKey)) == 1
+}
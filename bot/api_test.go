@@ -1,11 +1,14 @@
 package bot
 
 import (
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/bcneng/candebot/internal/jobs"
 	"github.com/stretchr/testify/require"
 )
 
@@ -78,3 +81,46 @@ func TestAPICreateChannel_BadRequest(t *testing.T) {
 		})
 	}
 }
+
+func TestAPIJobsStats(t *testing.T) {
+	store, err := jobs.NewStore("")
+	require.NoError(t, err)
+	require.NoError(t, store.Add(jobs.Post{Company: "Acme", Currency: "EUR", MaxSalary: 50, PublishedAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}))
+	require.NoError(t, store.Add(jobs.Post{Company: "Acme", Currency: "EUR", MaxSalary: 50, PublishedAt: time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)}))
+
+	handler := apiJobsStatsHandler(Context{
+		Config:   Config{APIKey: "test-secret-key"},
+		JobPosts: store,
+	})
+
+	tests := []struct {
+		name   string
+		method string
+		query  string
+		auth   string
+		status int
+		total  int
+	}{
+		{name: "all time", method: http.MethodGet, auth: "Bearer test-secret-key", status: http.StatusOK, total: 2},
+		{name: "by year", method: http.MethodGet, query: "?year=2024", auth: "Bearer test-secret-key", status: http.StatusOK, total: 1},
+		{name: "invalid year", method: http.MethodGet, query: "?year=last", auth: "Bearer test-secret-key", status: http.StatusBadRequest},
+		{name: "unauthorized", method: http.MethodGet, auth: "Bearer wrong-key", status: http.StatusUnauthorized},
+		{name: "method not allowed", method: http.MethodPost, auth: "Bearer test-secret-key", status: http.StatusMethodNotAllowed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, "/api/jobs/stats"+tt.query, nil)
+			req.Header.Set("Authorization", tt.auth)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+			require.Equal(t, tt.status, rec.Code)
+
+			if tt.status == http.StatusOK {
+				var stats jobs.Stats
+				require.NoError(t, json.NewDecoder(rec.Body).Decode(&stats))
+				require.Equal(t, tt.total, stats.Total)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bcneng/candebot/internal/audit"
+	"github.com/bcneng/candebot/slackx"
+)
+
+// Audit records the privileged action in the audit log, with its outcome set from the given error.
+// High severity actions are also mirrored to the staff channel.
+func Audit(botContext Context, e audit.Entry, err error) {
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	if e.Severity == "" {
+		e.Severity = audit.SeverityInfo
+	}
+	e.Outcome = audit.OutcomeSuccess
+	if err != nil {
+		e.Outcome = audit.OutcomeFailure
+		e.Error = err.Error()
+	}
+
+	log.Printf("[AUDIT] %s", formatAuditEntry(e))
+
+	if botContext.AuditLog != nil {
+		if err := botContext.AuditLog.Append(e); err != nil {
+			log.Printf("[ERROR] Failed to record %s action of %s in the audit log: %s", e.Action, e.Actor, err)
+		}
+	}
+
+	if e.Severity == audit.SeverityHigh && botContext.Client != nil {
+		_ = slackx.Send(botContext.Client, "", botContext.Config.Channels.Staff, ":shield: "+formatAuditEntry(e), false)
+	}
+}
+
+// FormatAuditEntries lists the given audit entries, one per line.
+func FormatAuditEntries(entries []audit.Entry) string {
+	if len(entries) == 0 {
+		return "No audited actions found."
+	}
+
+	lines := make([]string, 0, len(entries))
+	for _, e := range entries {
+		lines = append(lines, fmt.Sprintf("• %s %s", e.At.Format("2006-01-02 15:04"), formatAuditEntry(e)))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatAuditEntry(e audit.Entry) string {
+	msg := fmt.Sprintf("%s ran `%s`", formatAuditActor(e.Actor), e.Action)
+	if e.Target != "" {
+		msg += fmt.Sprintf(" on %s", e.Target)
+	}
+
+	if len(e.Params) > 0 {
+		keys := make([]string, 0, len(e.Params))
+		for k := range e.Params {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		params := make([]string, 0, len(keys))
+		for _, k := range keys {
+			params = append(params, fmt.Sprintf("%s=%q", k, e.Params[k]))
+		}
+		msg += fmt.Sprintf(" (%s)", strings.Join(params, ", "))
+	}
+
+	if e.Outcome == audit.OutcomeFailure {
+		return msg + fmt.Sprintf(": failed with %s", e.Error)
+	}
+
+	return msg + ": " + e.Outcome
+}
+
+// formatAuditActor mentions the actor if it is a Slack user.
+func formatAuditActor(actor string) string {
+	if _, ok := slackx.ParseUserMention(actor); ok {
+		return fmt.Sprintf("<@%s>", actor)
+	}
+
+	return actor
+}
@@ -0,0 +1,45 @@
+package bot
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/bcneng/candebot/internal/audit"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAudit(t *testing.T) {
+	var mirrored []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		mirrored = append(mirrored, r.PostForm.Get("text"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok": true, "channel": "CSTAFF", "ts": "1.1"}`))
+	}))
+	defer server.Close()
+
+	auditLog, err := audit.NewLog("")
+	require.NoError(t, err)
+
+	botContext := Context{
+		Client:   slack.New("test-token", slack.OptionAPIURL(server.URL+"/")),
+		AuditLog: auditLog,
+		Config:   Config{Channels: ConfigChannels{Staff: "CSTAFF"}},
+	}
+
+	Audit(botContext, audit.Entry{Actor: audit.ActorBot, Action: "rate_limit_message_deleted", Target: "CRANDOM"}, nil)
+	require.Empty(t, mirrored, "info actions must not be mirrored")
+
+	Audit(botContext, audit.Entry{Actor: "USTAFF", Action: "echo", Target: "<#CGENERAL>", Params: map[string]string{"message": "Hi!"}, Severity: audit.SeverityHigh}, errors.New("channel_not_found"))
+	require.Equal(t, []string{`:shield: <@USTAFF> ran ` + "`echo`" + ` on <#CGENERAL> (message="Hi!"): failed with channel_not_found`}, mirrored)
+
+	entries := auditLog.Recent(10, audit.Filter{})
+	require.Len(t, entries, 2)
+	require.Equal(t, audit.OutcomeFailure, entries[0].Outcome)
+	require.Equal(t, audit.OutcomeSuccess, entries[1].Outcome)
+	require.Equal(t, audit.SeverityInfo, entries[1].Severity)
+	require.False(t, entries[1].At.IsZero())
+}
@@ -6,11 +6,21 @@ import (
 	"fmt"
 	"log"
 	"net/http"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/asaskevich/EventBus"
 
+	"github.com/bcneng/candebot/inclusion"
+	"github.com/bcneng/candebot/internal/audit"
+	"github.com/bcneng/candebot/internal/crypto"
+	"github.com/bcneng/candebot/internal/jobs"
+	"github.com/bcneng/candebot/internal/moderation"
+	"github.com/bcneng/candebot/internal/nudges"
+	"github.com/bcneng/candebot/internal/oauth"
 	"github.com/bcneng/candebot/internal/privacy"
+	"github.com/bcneng/candebot/internal/reports"
 	"github.com/bcneng/candebot/slackx"
 
 	"github.com/newrelic/newrelic-telemetry-sdk-go/telemetry"
@@ -18,7 +28,7 @@ import (
 )
 
 // WakeUp wakes up the bot.
-func WakeUp(_ context.Context, conf Config, bus EventBus.Bus) error {
+func WakeUp(ctx context.Context, conf Config, bus EventBus.Bus) error {
 	client := slack.New(conf.Bot.UserToken)
 	cliContext := Context{
 		Client:      client,
@@ -43,6 +53,12 @@ func WakeUp(_ context.Context, conf Config, bus EventBus.Bus) error {
 		log.Println("[WARN] No metrics will be sent to NR as there is no License Key configured")
 	}
 
+	auditLog, err := audit.NewLog(filepath.Join(conf.DataDir, "audit.jsonl"))
+	if err != nil {
+		return err
+	}
+	cliContext.AuditLog = auditLog
+
 	channelResolver := slackx.NewChannelResolver(http.DefaultClient, client)
 	cliContext.ChannelResolver = channelResolver
 
@@ -70,9 +86,175 @@ func WakeUp(_ context.Context, conf Config, bus EventBus.Bus) error {
 	}
 	cliContext.TrackingDetector = trackingDetector
 
+	inclusionRules, err := inclusion.LoadRules(conf.InclusionRules...)
+	if err != nil {
+		return err
+	}
+	for i, rule := range inclusionRules {
+		// Rules are enabled in channels by name, while messages come with the channel ID.
+		for j, name := range rule.Channels {
+			id, err := channelResolver.FindChannelIDByName(name)
+			if err != nil {
+				return fmt.Errorf("inclusion rule %q channel %q: %w", rule.Filter, name, err)
+			}
+			inclusionRules[i].Channels[j] = id
+		}
+	}
+	inclusionRuleset, err := inclusion.NewRuleset(inclusionRules...)
+	if err != nil {
+		return err
+	}
+	cliContext.InclusionRules = inclusionRuleset
+
+	cliContext.InclusionChannels = make(map[string]InclusionChannel, len(conf.Inclusion))
+	for _, cfg := range conf.Inclusion {
+		id, err := channelResolver.FindChannelIDByName(cfg.ChannelName)
+		if err != nil {
+			return fmt.Errorf("inclusion channel %q: %w", cfg.ChannelName, err)
+		}
+
+		channel := InclusionChannel{InclusionConfig: cfg}
+		if len(cfg.RuleSets) > 0 {
+			if channel.Rules, err = inclusionRuleset.Only(cfg.RuleSets...); err != nil {
+				return fmt.Errorf("inclusion channel %q: %w", cfg.ChannelName, err)
+			}
+		}
+		cliContext.InclusionChannels[id] = channel
+	}
+	cliContext.NudgeCooldown = nudges.NewCooldown()
+
+	jobPosts, err := jobs.NewStore(filepath.Join(conf.DataDir, "job_posts.json"))
+	if err != nil {
+		return err
+	}
+	cliContext.JobPosts = jobPosts
+
+	exchangeRates, err := jobs.LoadExchangeRates(conf.Jobs.ExchangeRatesFile)
+	if err != nil {
+		return err
+	}
+	cliContext.ExchangeRates = exchangeRates
+	for _, currency := range conf.Jobs.Currencies {
+		if _, ok := exchangeRates[currency]; !ok {
+			log.Printf("[WARN] No exchange rate for %s. Salaries in %s won't be normalized to EUR", currency, currency)
+		}
+	}
+
+	cliContext.JobPolicy = jobs.NewPolicy(jobs.PolicyConfig{
+		Blocklist:              conf.Jobs.Policy.Blocklist,
+		AgencyPublisher:        conf.Jobs.Policy.AgencyPublisher,
+		AgencyRequiresApproval: conf.Jobs.Policy.AgencyRequiresApproval,
+		Moderated:              conf.Jobs.Policy.Moderated,
+	})
+	jobQueue, err := jobs.NewQueue(filepath.Join(conf.DataDir, "job_post_submissions.json"))
+	if err != nil {
+		return err
+	}
+	cliContext.JobQueue = jobQueue
+
+	jobSubscriptions, err := jobs.NewSubscriptions(filepath.Join(conf.DataDir, "job_subscriptions.json"))
+	if err != nil {
+		return err
+	}
+	cliContext.JobSubscriptions = jobSubscriptions
+
+	reportCases, err := reports.NewStore(filepath.Join(conf.DataDir, "reports.json"))
+	if err != nil {
+		return err
+	}
+	cliContext.Reports = reportCases
+
+	if conf.Reports.EncryptionKey != "" {
+		reportCipher, err := crypto.NewCipher(conf.Reports.EncryptionKey)
+		if err != nil {
+			return err
+		}
+		cliContext.ReportCipher = reportCipher
+	} else {
+		log.Println("[WARN] Anonymous reports are disabled as there is no encryption key configured")
+	}
+
+	moderationHistory, err := moderation.NewHistory(filepath.Join(conf.DataDir, "moderation_history.json"))
+	if err != nil {
+		return err
+	}
+	cliContext.ModerationHistory = moderationHistory
+
+	sanctions, err := moderation.NewSanctions(filepath.Join(conf.DataDir, "moderation_sanctions.json"))
+	if err != nil {
+		return err
+	}
+	cliContext.Sanctions = sanctions
+
+	if len(conf.Moderation.Channels) > 0 {
+		cliContext.SanctionChannels = make(map[string]struct{}, len(conf.Moderation.Channels))
+		for _, name := range conf.Moderation.Channels {
+			id, err := channelResolver.FindChannelIDByName(name)
+			if err != nil {
+				return fmt.Errorf("moderation channel %q: %w", name, err)
+			}
+			cliContext.SanctionChannels[id] = struct{}{}
+		}
+	}
+	go liftExpiredSanctions(ctx, cliContext)
+
+	nudgePreferences, err := nudges.NewPreferences(filepath.Join(conf.DataDir, "inclusion_nudges.json"))
+	if err != nil {
+		return err
+	}
+	cliContext.Nudges = nudgePreferences
+
+	inclusionStats, err := nudges.NewStats(filepath.Join(conf.DataDir, "inclusion_stats.json"))
+	if err != nil {
+		return err
+	}
+	cliContext.InclusionStats = inclusionStats
+
+	if conf.OAuth.Enabled() {
+		tokenCipher, err := crypto.NewCipher(conf.OAuth.EncryptionKey)
+		if err != nil {
+			return err
+		}
+		userTokens, err := oauth.NewTokens(filepath.Join(conf.DataDir, "oauth_tokens.json"), tokenCipher)
+		if err != nil {
+			return err
+		}
+		cliContext.UserTokens = userTokens
+	} else {
+		log.Println("[WARN] Inclusive language suggestions can't be applied on behalf of members as there is no OAuth config")
+	}
+
+	if timeout := intValue(conf.Jobs.LinkCheckTimeoutSeconds); timeout > 0 {
+		cliContext.JobLinkChecker = jobs.NewLinkChecker(nil, time.Duration(timeout)*time.Second)
+	}
+
+	if conf.Jobs.Digest.Enabled {
+		go scheduleJobsDigest(ctx, cliContext)
+	}
+
 	return serve(conf, cliContext)
 }
 
+// NewCLIContext creates the context to run commands from the command line, e.g. `candebot inclusion test "hey guys"`.
+// There is no connection to Slack, so only commands not needing it are supported.
+func NewCLIContext(conf Config) (Context, error) {
+	inclusionRules, err := inclusion.LoadRules(conf.InclusionRules...)
+	if err != nil {
+		return Context{}, err
+	}
+	inclusionRuleset, err := inclusion.NewRuleset(inclusionRules...)
+	if err != nil {
+		return Context{}, err
+	}
+
+	return Context{
+		Config:         conf,
+		Version:        conf.Version,
+		InclusionRules: inclusionRuleset,
+		CLI:            true,
+	}, nil
+}
+
 func serve(conf Config, cliContext Context) error {
 	http.HandleFunc("/healthz", func(w http.ResponseWriter, _ *http.Request) {
 		w.WriteHeader(http.StatusOK)
@@ -106,11 +288,16 @@ func serve(conf Config, cliContext Context) error {
 
 	if conf.APIKey != "" {
 		http.HandleFunc("/api/channels", apiCreateChannelHandler(cliContext))
+		http.HandleFunc("/api/jobs/stats", apiJobsStatsHandler(cliContext))
 	}
 
 	http.HandleFunc("/events", eventsAPIHandler(cliContext))
 	http.HandleFunc("/interact", interactAPIHandler(cliContext))
 
+	if cliContext.UserTokens != nil {
+		http.HandleFunc("/oauth/callback", oauthCallbackHandler(cliContext))
+	}
+
 	log.Println("[INFO] Slash server listening on port", conf.Bot.Server.Port)
 
 	return http.ListenAndServe(fmt.Sprintf(":%d", conf.Bot.Server.Port), nil)
@@ -2,11 +2,16 @@ package bot
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path"
 	"strings"
 
+	"github.com/bcneng/candebot/inclusion"
+	"github.com/bcneng/candebot/internal/crypto"
+	"github.com/bcneng/candebot/internal/jobs"
+	"github.com/bcneng/candebot/internal/moderation"
 	"github.com/bcneng/twitter-contest/twitter"
 	"github.com/pelletier/go-toml/v2"
 	"github.com/sethvargo/go-envconfig"
@@ -47,7 +52,43 @@ func LoadConfigFromFileAndEnvVars(ctx context.Context, envVarsPrefix, filepath s
 		return err
 	}
 
-	return LoadConfigFromEnvVars(ctx, envVarsPrefix, conf)
+	if err := LoadConfigFromEnvVars(ctx, envVarsPrefix, conf); err != nil {
+		return err
+	}
+
+	conf.Jobs.applyDefaults()
+	conf.Moderation.applyDefaults()
+
+	return conf.Validate()
+}
+
+// Validate checks the config is consistent. Any error found should prevent the bot to start.
+func (c Config) Validate() error {
+	if err := c.Jobs.Validate(); err != nil {
+		return fmt.Errorf("jobs config: %w", err)
+	}
+
+	if c.Reports.EncryptionKey != "" {
+		if _, err := crypto.NewCipher(c.Reports.EncryptionKey); err != nil {
+			return fmt.Errorf("reports config: %w", err)
+		}
+	}
+
+	if err := c.Moderation.validate(); err != nil {
+		return fmt.Errorf("moderation config: %w", err)
+	}
+
+	if err := c.OAuth.validate(); err != nil {
+		return fmt.Errorf("oauth config: %w", err)
+	}
+
+	for _, inclusionConfig := range c.Inclusion {
+		if err := inclusionConfig.validate(); err != nil {
+			return fmt.Errorf("inclusion config: %w", err)
+		}
+	}
+
+	return nil
 }
 
 type Config struct {
@@ -56,12 +97,19 @@ type Config struct {
 	Channels            ConfigChannels            `env:",prefix=CHANNELS_"`
 	Links               ConfigLinks               `env:",prefix=LINKS_"`
 	Twitter             ConfigTwitter             `env:",prefix=TWITTER_"`
+	Jobs                ConfigJobs                `env:",prefix=JOBS_" toml:"jobs"`
+	Reports             ConfigReports             `env:",prefix=REPORTS_" toml:"reports"`
+	Moderation          ConfigModeration          `env:",prefix=MODERATION_" toml:"moderation"`
+	OAuth               ConfigOAuth               `env:",prefix=OAUTH_" toml:"oauth"`
 	RateLimits          []RateLimitConfig         `toml:"rate_limits"`
 	TrackingDetection   []TrackingDetectionConfig `toml:"tracking_detection"`
+	InclusionRules      []string                  `env:"INCLUSION_RULES" toml:"inclusion_rules"` // Paths to TOML or YAML files with extra inclusive language rules. See inclusion.LoadRules.
+	Inclusion           []InclusionConfig         `toml:"inclusion"`
 	TwitterContestToken string                    `env:"TWITTER_CONTEST_TOKEN"`
 	TwitterContestURL   string                    `env:"TWITTER_CONTEST_URL"`
 	NewRelicLicenseKey  string                    `env:"NEW_RELIC_LICENSE_KEY"`
 	APIKey              string                    `env:"API_KEY"`
+	DataDir             string                    `env:"DATA_DIR,default=./data" toml:"data_dir"`
 	Debug               bool                      `env:"DEBUG"`
 	Version             string                    `env:"VERSION"`
 }
@@ -103,6 +151,313 @@ type ConfigTwitter struct {
 	APIKeySecret string `env:"API_KEY_SECRET"`
 }
 
+type ConfigReports struct {
+	// EncryptionKey is the base64 encoded AES-256 key used to encrypt the identity of anonymous reporters.
+	// Anonymous reports are not offered if empty. Sensitive, so only read from env vars.
+	EncryptionKey string `env:"ENCRYPTION_KEY" toml:"-"`
+}
+
+// ConfigOAuth configures the Slack OAuth flow members go through to let the bot edit their messages, e.g. to apply
+// inclusive language suggestions. It's disabled unless every field is set.
+type ConfigOAuth struct {
+	ClientID     string `env:"CLIENT_ID" toml:"client_id"`
+	ClientSecret string `env:"CLIENT_SECRET" toml:"-"`
+	// RedirectURL is the public URL of the /oauth/callback endpoint of the bot, as registered in the Slack app.
+	RedirectURL string `env:"REDIRECT_URL" toml:"redirect_url"`
+	// EncryptionKey is the base64 encoded AES-256 key used to encrypt the stored user tokens. Sensitive, so only read from env vars.
+	EncryptionKey string `env:"ENCRYPTION_KEY" toml:"-"`
+}
+
+// Enabled returns true if the OAuth flow is configured.
+func (c ConfigOAuth) Enabled() bool {
+	return c.ClientID != "" && c.ClientSecret != "" && c.RedirectURL != "" && c.EncryptionKey != ""
+}
+
+func (c ConfigOAuth) validate() error {
+	if c == (ConfigOAuth{}) {
+		return nil
+	}
+	if !c.Enabled() {
+		return errors.New("client_id, client secret, redirect_url and encryption key are all required")
+	}
+	if _, err := crypto.NewCipher(c.EncryptionKey); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type ConfigModeration struct {
+	// AlertThreshold is the number of moderation events (reports, rate limit deletions, inclusion and tracking
+	// warnings) of a single member within the alert window that triggers an alert in the staff channel.
+	AlertThreshold int `env:"ALERT_THRESHOLD,default=5" toml:"alert_threshold"`
+	// AlertWindowDays is the number of days moderation events are counted for the alert.
+	AlertWindowDays int `env:"ALERT_WINDOW_DAYS,default=30" toml:"alert_window_days"`
+	// Channels are the names of the channels where timeouts and restrictions apply. Empty applies them in every channel.
+	Channels []string `toml:"channels"`
+	// CoCSections are the Code of Conduct sections formal warnings can refer to. See moderation.DefaultCoCSections for the defaults.
+	CoCSections []moderation.CoCSection `toml:"coc_sections"`
+}
+
+func (c *ConfigModeration) applyDefaults() {
+	if len(c.CoCSections) == 0 {
+		c.CoCSections = moderation.DefaultCoCSections
+	}
+}
+
+func (c ConfigModeration) validate() error {
+	if c.AlertThreshold <= 0 {
+		return fmt.Errorf("alert_threshold should be positive, got %d", c.AlertThreshold)
+	}
+	if c.AlertWindowDays <= 0 {
+		return fmt.Errorf("alert_window_days should be positive, got %d", c.AlertWindowDays)
+	}
+
+	seen := make(map[string]struct{}, len(c.CoCSections))
+	for _, s := range c.CoCSections {
+		if s.Key == "" || s.Title == "" || s.Text == "" {
+			return errors.New("coc_sections should have key, title and text")
+		}
+		key := strings.ToLower(s.Key)
+		if _, ok := seen[key]; ok {
+			return fmt.Errorf("coc_sections key %q is duplicated", s.Key)
+		}
+		seen[key] = struct{}{}
+	}
+
+	return nil
+}
+
+type ConfigJobs struct {
+	// DuplicateWindowDays is the number of days a job post is considered when looking for duplicated submissions.
+	// Zero disables duplicate detection. Defaults to 30 when unset.
+	DuplicateWindowDays *int `env:"DUPLICATE_WINDOW_DAYS,noinit" toml:"duplicate_window_days"`
+	// LinkCheckTimeoutSeconds is the time to wait for the job link to respond. Keep it under 3 seconds,
+	// as Slack expects submissions to be answered within that time. Zero disables the link check. Defaults to 2 when unset.
+	LinkCheckTimeoutSeconds *int `env:"LINK_CHECK_TIMEOUT_SECONDS,noinit" toml:"link_check_timeout_seconds"`
+	// ExchangeRatesFile is the path to a TOML file with the value in EUR of each currency. See jobs.LoadExchangeRates.
+	ExchangeRatesFile string `env:"EXCHANGE_RATES_FILE" toml:"exchange_rates_file"`
+	// MinSalary is the minimum yearly salary allowed per currency, in thousands.
+	MinSalary map[string]int `toml:"min_salary"`
+	// Options offered in the job submission form. See DefaultConfigJobs for the defaults.
+	Locations  []ConfigJobsOption    `toml:"locations"`
+	Currencies []string              `toml:"currencies"`
+	Publishers []ConfigJobsOption    `toml:"publishers"`
+	Role       ConfigJobsFieldLength `toml:"role"`
+	Company    ConfigJobsFieldLength `toml:"company"`
+	Policy     ConfigJobsPolicy      `env:",prefix=POLICY_" toml:"policy"`
+	Digest     ConfigJobsDigest      `env:",prefix=DIGEST_" toml:"digest"`
+}
+
+type ConfigJobsPolicy struct {
+	// Blocklist contains the company or agency names not allowed to post.
+	Blocklist []string `toml:"blocklist"`
+	// AgencyPublisher is the publisher option value identifying agency posts. Defaults to "Agency" if offered.
+	AgencyPublisher string `toml:"agency_publisher"`
+	// AgencyRequiresApproval sends agency posts to the staff channel for approval before publishing them.
+	AgencyRequiresApproval bool `toml:"agency_requires_approval"`
+	// Moderated sends every post to the staff channel for approval before publishing it.
+	Moderated bool `env:"MODERATED" toml:"moderated"`
+}
+
+type ConfigJobsDigest struct {
+	// Enabled posts every Monday a digest of the job posts published the previous week.
+	Enabled bool `env:"ENABLED" toml:"enabled"`
+	// Channel is where the digest is posted: "jobs" or "general". Defaults to "jobs".
+	Channel string `env:"CHANNEL" toml:"channel"`
+	// Hour is the hour of the day (Europe/Madrid) the digest is posted at. Defaults to 9 when unset.
+	Hour *int `env:"HOUR,noinit" toml:"hour"`
+	// SalaryBands are the upper limits, in thousands of EUR, of the salary bands posts are grouped by.
+	SalaryBands []int `toml:"salary_bands"`
+}
+
+// Supported values of ConfigJobsDigest.Channel.
+const (
+	JobsDigestChannelJobs    = "jobs"
+	JobsDigestChannelGeneral = "general"
+)
+
+type ConfigJobsOption struct {
+	Label string `toml:"label"`
+	Value string `toml:"value"`
+}
+
+type ConfigJobsFieldLength struct {
+	MinLength int `toml:"min_length"`
+	MaxLength int `toml:"max_length"`
+}
+
+// DefaultConfigJobs holds the default job submission form options, used for any option left empty in the config.
+var DefaultConfigJobs = ConfigJobs{
+	Locations: []ConfigJobsOption{
+		{Label: "Barcelona", Value: "Barcelona"},
+		{Label: "Barcelona/Remote", Value: "Barcelona/Remote"},
+		{Label: "Remote", Value: "Remote"},
+	},
+	Currencies: []string{"EUR", "USD", "GBP", "CHF"},
+	Publishers: []ConfigJobsOption{
+		{Label: "Employer", Value: "Employer"},
+		{Label: "Agency", Value: "Agency"},
+		{Label: "Referral", Value: "Referral"},
+	},
+	Role:                    ConfigJobsFieldLength{MinLength: 2, MaxLength: 50},
+	Company:                 ConfigJobsFieldLength{MinLength: 2, MaxLength: 20},
+	Digest:                  ConfigJobsDigest{Channel: JobsDigestChannelJobs, Hour: intPtr(9), SalaryBands: jobs.DefaultSalaryBands},
+	DuplicateWindowDays:     intPtr(30),
+	LinkCheckTimeoutSeconds: intPtr(2),
+}
+
+func (c *ConfigJobs) applyDefaults() {
+	// Optional numbers are pointers, so an explicit 0 in the config is not replaced by the default.
+	if c.DuplicateWindowDays == nil {
+		c.DuplicateWindowDays = intPtr(*DefaultConfigJobs.DuplicateWindowDays)
+	}
+	if c.LinkCheckTimeoutSeconds == nil {
+		c.LinkCheckTimeoutSeconds = intPtr(*DefaultConfigJobs.LinkCheckTimeoutSeconds)
+	}
+	if len(c.Locations) == 0 {
+		c.Locations = DefaultConfigJobs.Locations
+	}
+	if len(c.Currencies) == 0 {
+		c.Currencies = DefaultConfigJobs.Currencies
+	}
+	if len(c.Publishers) == 0 {
+		c.Publishers = DefaultConfigJobs.Publishers
+	}
+	if c.Role == (ConfigJobsFieldLength{}) {
+		c.Role = DefaultConfigJobs.Role
+	}
+	if c.Company == (ConfigJobsFieldLength{}) {
+		c.Company = DefaultConfigJobs.Company
+	}
+	if c.Digest.Hour == nil {
+		c.Digest.Hour = intPtr(*DefaultConfigJobs.Digest.Hour)
+	}
+	if c.Digest.Channel == "" {
+		c.Digest.Channel = DefaultConfigJobs.Digest.Channel
+	}
+	if len(c.Digest.SalaryBands) == 0 {
+		c.Digest.SalaryBands = DefaultConfigJobs.Digest.SalaryBands
+	}
+	if _, ok := findJobsOption(c.Publishers, "Agency"); ok && c.Policy.AgencyPublisher == "" {
+		c.Policy.AgencyPublisher = "Agency"
+	}
+}
+
+// maxDialogOptions and maxDialogTextLength are the limits imposed by Slack dialogs.
+const (
+	maxDialogOptions    = 100
+	maxDialogTextLength = 150
+)
+
+// Validate checks the job submission form options are usable in a Slack dialog.
+func (c ConfigJobs) Validate() error {
+	if err := validateJobsOptions("locations", c.Locations); err != nil {
+		return err
+	}
+	if err := validateJobsOptions("publishers", c.Publishers); err != nil {
+		return err
+	}
+
+	currencies := make([]ConfigJobsOption, 0, len(c.Currencies))
+	for _, currency := range c.Currencies {
+		if len(currency) != 3 || strings.ToUpper(currency) != currency {
+			return fmt.Errorf("currency %q should be an uppercase ISO 4217 code", currency)
+		}
+		currencies = append(currencies, ConfigJobsOption{Label: currency, Value: currency})
+	}
+	if err := validateJobsOptions("currencies", currencies); err != nil {
+		return err
+	}
+
+	for currency := range c.MinSalary {
+		if !c.HasCurrency(currency) {
+			return fmt.Errorf("min_salary set for %s, which is not one of the configured currencies", currency)
+		}
+	}
+
+	if _, ok := findJobsOption(c.Publishers, c.Policy.AgencyPublisher); c.Policy.AgencyPublisher != "" && !ok {
+		return fmt.Errorf("policy agency_publisher %q is not one of the configured publishers", c.Policy.AgencyPublisher)
+	}
+
+	if err := c.Role.validate("role"); err != nil {
+		return err
+	}
+	if err := c.Company.validate("company"); err != nil {
+		return err
+	}
+
+	return c.Digest.validate()
+}
+
+func (d ConfigJobsDigest) validate() error {
+	if d.Channel != JobsDigestChannelJobs && d.Channel != JobsDigestChannelGeneral {
+		return fmt.Errorf("digest channel should be either %q or %q, got %q", JobsDigestChannelJobs, JobsDigestChannelGeneral, d.Channel)
+	}
+	if hour := intValue(d.Hour); hour < 0 || hour > 23 {
+		return fmt.Errorf("digest hour should be within 0-23, got %d", hour)
+	}
+	for i, band := range d.SalaryBands {
+		if band <= 0 || i > 0 && band <= d.SalaryBands[i-1] {
+			return fmt.Errorf("digest salary_bands should be positive and sorted ascending, got %v", d.SalaryBands)
+		}
+	}
+
+	return nil
+}
+
+// HasCurrency returns true if the given currency is offered in the form.
+func (c ConfigJobs) HasCurrency(currency string) bool {
+	for _, cur := range c.Currencies {
+		if cur == currency {
+			return true
+		}
+	}
+
+	return false
+}
+
+func validateJobsOptions(name string, options []ConfigJobsOption) error {
+	if len(options) == 0 {
+		return fmt.Errorf("%s should have at least one option", name)
+	}
+	if len(options) > maxDialogOptions {
+		return fmt.Errorf("%s should have at most %d options", name, maxDialogOptions)
+	}
+
+	seen := make(map[string]struct{}, len(options))
+	for _, o := range options {
+		if o.Label == "" || o.Value == "" {
+			return fmt.Errorf("%s options should have both label and value", name)
+		}
+		if _, ok := seen[o.Value]; ok {
+			return fmt.Errorf("%s option %q is duplicated", name, o.Value)
+		}
+		seen[o.Value] = struct{}{}
+	}
+
+	return nil
+}
+
+func (l ConfigJobsFieldLength) validate(name string) error {
+	if l.MinLength < 0 || l.MaxLength <= 0 || l.MinLength > l.MaxLength || l.MaxLength > maxDialogTextLength {
+		return fmt.Errorf("%s length should be within 0 <= min_length <= max_length <= %d, got %d-%d", name, maxDialogTextLength, l.MinLength, l.MaxLength)
+	}
+
+	return nil
+}
+
+// findJobsOption returns the option matching the given value, if any.
+func findJobsOption(options []ConfigJobsOption, value string) (ConfigJobsOption, bool) {
+	for _, o := range options {
+		if o.Value == value {
+			return o, true
+		}
+	}
+
+	return ConfigJobsOption{}, false
+}
+
 type RateLimitConfig struct {
 	ChannelName      string `toml:"channel_name"`
 	RateLimitSeconds int    `toml:"rate_limit_seconds"`
@@ -110,6 +465,48 @@ type RateLimitConfig struct {
 	ApplyToStaff     bool   `toml:"apply_to_staff"`
 }
 
+type InclusionConfig struct {
+	ChannelName string `toml:"channel_name"`
+	// Disabled turns the inclusive language checks off in the channel.
+	Disabled bool `toml:"disabled"`
+	// Language is the language (en, es or ca) messages of the channel are assumed to be written in when it can't be detected.
+	Language string `toml:"language"`
+	// RuleSets are the rule sets checked in the channel: "builtin" and the sets of the rules files. Empty checks all of them.
+	RuleSets []string `toml:"rule_sets"`
+	// CooldownMinutes is the minimum time between two nudges to the same member in the channel. 0 disables it.
+	CooldownMinutes int `toml:"cooldown_minutes"`
+}
+
+func (c InclusionConfig) validate() error {
+	if c.ChannelName == "" {
+		return errors.New("channel_name is required")
+	}
+
+	switch c.Language {
+	case "", inclusion.LanguageEnglish, inclusion.LanguageSpanish, inclusion.LanguageCatalan:
+	default:
+		return fmt.Errorf("channel %s language should be one of %s, %s or %s, got %q", c.ChannelName, inclusion.LanguageEnglish, inclusion.LanguageSpanish, inclusion.LanguageCatalan, c.Language)
+	}
+
+	if c.CooldownMinutes < 0 {
+		return fmt.Errorf("channel %s cooldown_minutes should not be negative", c.ChannelName)
+	}
+
+	return nil
+}
+
 type TrackingDetectionConfig struct {
 	ChannelName string `toml:"channel_name"`
 }
+
+func intPtr(i int) *int {
+	return &i
+}
+
+// intValue returns the value of an optional number of the config, or zero if it's unset.
+func intValue(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bcneng/candebot/internal/moderation"
+	"github.com/sethvargo/go-envconfig"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConfigJobsDefaults(t *testing.T) {
+	var conf ConfigJobs
+	conf.applyDefaults()
+	require.Equal(t, DefaultConfigJobs.Locations, conf.Locations)
+	require.Equal(t, DefaultConfigJobs.Currencies, conf.Currencies)
+	require.Equal(t, DefaultConfigJobs.Publishers, conf.Publishers)
+	require.Equal(t, DefaultConfigJobs.Role, conf.Role)
+	require.Equal(t, DefaultConfigJobs.Company, conf.Company)
+	require.Equal(t, "Agency", conf.Policy.AgencyPublisher)
+	require.Equal(t, DefaultConfigJobs.Digest.Channel, conf.Digest.Channel)
+	require.Equal(t, DefaultConfigJobs.Digest.SalaryBands, conf.Digest.SalaryBands)
+	require.NoError(t, conf.Validate())
+
+	conf = ConfigJobs{Currencies: []string{"EUR"}}
+	conf.applyDefaults()
+	require.Equal(t, []string{"EUR"}, conf.Currencies, "configured options must be kept")
+}
+
+func TestConfigJobsOptionalNumbers(t *testing.T) {
+	// Env vars are loaded after the TOML file, as LoadConfigFromFileAndEnvVars does.
+	load := func(t *testing.T, toml string, env map[string]string) ConfigJobs {
+		var conf Config
+		require.NoError(t, LoadConfigFromBytes([]byte(toml), &conf))
+		require.NoError(t, envconfig.ProcessWith(context.Background(), &conf.Jobs, envconfig.MapLookuper(env)))
+		conf.Jobs.applyDefaults()
+		return conf.Jobs
+	}
+
+	conf := load(t, "", nil)
+	require.Equal(t, 30, intValue(conf.DuplicateWindowDays))
+	require.Equal(t, 2, intValue(conf.LinkCheckTimeoutSeconds))
+	require.Equal(t, 9, intValue(conf.Digest.Hour))
+
+	conf = load(t, "[jobs]\nduplicate_window_days = 0\nlink_check_timeout_seconds = 0\n[jobs.digest]\nhour = 0", nil)
+	require.Equal(t, 0, intValue(conf.DuplicateWindowDays), "0 disables it and must not be replaced by the default")
+	require.Equal(t, 0, intValue(conf.LinkCheckTimeoutSeconds))
+	require.Equal(t, 0, intValue(conf.Digest.Hour), "midnight")
+
+	conf = load(t, "", map[string]string{"DUPLICATE_WINDOW_DAYS": "0"})
+	require.Equal(t, 0, intValue(conf.DuplicateWindowDays))
+}
+
+func TestConfigJobsValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(c *ConfigJobs)
+	}{
+		{name: "no locations", modify: func(c *ConfigJobs) { c.Locations = []ConfigJobsOption{} }},
+		{name: "option without value", modify: func(c *ConfigJobs) { c.Locations = []ConfigJobsOption{{Label: "Barcelona"}} }},
+		{name: "duplicated option", modify: func(c *ConfigJobs) {
+			c.Publishers = []ConfigJobsOption{{Label: "Employer", Value: "Employer"}, {Label: "Company", Value: "Employer"}}
+		}},
+		{name: "lowercase currency", modify: func(c *ConfigJobs) { c.Currencies = []string{"eur"} }},
+		{name: "min salary for an unknown currency", modify: func(c *ConfigJobs) { c.MinSalary = map[string]int{"JPY": 1} }},
+		{name: "min length greater than max length", modify: func(c *ConfigJobs) { c.Role = ConfigJobsFieldLength{MinLength: 10, MaxLength: 5} }},
+		{name: "agency publisher not offered", modify: func(c *ConfigJobs) { c.Policy.AgencyPublisher = "Recruiter" }},
+		{name: "unknown digest channel", modify: func(c *ConfigJobs) { c.Digest.Channel = "random" }},
+		{name: "digest hour out of range", modify: func(c *ConfigJobs) { c.Digest.Hour = intPtr(24) }},
+		{name: "unsorted digest salary bands", modify: func(c *ConfigJobs) { c.Digest.SalaryBands = []int{60, 40} }},
+		{name: "max length over the dialog limit", modify: func(c *ConfigJobs) { c.Company = ConfigJobsFieldLength{MinLength: 1, MaxLength: 151} }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var conf ConfigJobs
+			conf.applyDefaults()
+			tt.modify(&conf)
+			require.Error(t, conf.Validate())
+		})
+	}
+}
+
+func TestConfigModerationValidate(t *testing.T) {
+	conf := ConfigModeration{AlertThreshold: 5, AlertWindowDays: 30}
+	conf.applyDefaults()
+	require.Equal(t, moderation.DefaultCoCSections, conf.CoCSections)
+	require.NoError(t, conf.validate())
+
+	conf.CoCSections = []moderation.CoCSection{{Key: "spam", Title: "No spam", Text: "..."}, {Key: "SPAM", Title: "Spam", Text: "..."}}
+	require.Error(t, conf.validate(), "duplicated keys")
+
+	conf.CoCSections = []moderation.CoCSection{{Key: "spam"}}
+	require.Error(t, conf.validate(), "missing title and text")
+
+	conf.CoCSections = nil
+	conf.AlertThreshold = 0
+	require.Error(t, conf.validate())
+}
+
+func TestConfigOAuthValidate(t *testing.T) {
+	require.NoError(t, ConfigOAuth{}.validate(), "disabled")
+
+	conf := ConfigOAuth{
+		ClientID:      "123.456",
+		ClientSecret:  "secret",
+		RedirectURL:   "https://bot.example.com/oauth/callback",
+		EncryptionKey: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=",
+	}
+	require.True(t, conf.Enabled())
+	require.NoError(t, conf.validate())
+
+	conf.EncryptionKey = "c2hvcnQ="
+	require.Error(t, conf.validate(), "invalid key")
+
+	conf.EncryptionKey = ""
+	require.False(t, conf.Enabled())
+	require.Error(t, conf.validate(), "partially configured")
+}
+
+func TestInclusionConfigValidate(t *testing.T) {
+	conf := InclusionConfig{ChannelName: "catala", Language: "ca", RuleSets: []string{"builtin"}, CooldownMinutes: 60}
+	require.NoError(t, conf.validate())
+
+	conf.CooldownMinutes = -1
+	require.Error(t, conf.validate(), "negative cooldown")
+
+	conf.CooldownMinutes = 0
+	conf.Language = "fr"
+	require.Error(t, conf.validate(), "unsupported language")
+}
@@ -4,7 +4,15 @@ import (
 	"net/http"
 
 	"github.com/asaskevich/EventBus"
+	"github.com/bcneng/candebot/inclusion"
+	"github.com/bcneng/candebot/internal/audit"
+	"github.com/bcneng/candebot/internal/crypto"
+	"github.com/bcneng/candebot/internal/jobs"
+	"github.com/bcneng/candebot/internal/moderation"
+	"github.com/bcneng/candebot/internal/nudges"
+	"github.com/bcneng/candebot/internal/oauth"
 	"github.com/bcneng/candebot/internal/privacy"
+	"github.com/bcneng/candebot/internal/reports"
 	"github.com/bcneng/candebot/slackx"
 	"github.com/newrelic/newrelic-telemetry-sdk-go/telemetry"
 	"github.com/slack-go/slack"
@@ -21,6 +29,24 @@ type Context struct {
 	RateLimiter         *RateLimiter
 	ChannelResolver     *slackx.ChannelResolver
 	TrackingDetector    *privacy.TrackingDetector
+	JobPosts            *jobs.Store
+	JobLinkChecker      *jobs.LinkChecker
+	ExchangeRates       jobs.ExchangeRates
+	JobPolicy           jobs.Policy
+	JobQueue            *jobs.Queue
+	JobSubscriptions    *jobs.Subscriptions
+	Reports             *reports.Store
+	ReportCipher        *crypto.Cipher // Nil if anonymous reports are disabled.
+	ModerationHistory   *moderation.History
+	Sanctions           *moderation.Sanctions
+	SanctionChannels    map[string]struct{} // IDs of the channels where sanctions apply. Nil if they apply everywhere.
+	AuditLog            *audit.Log
+	InclusionRules      *inclusion.Ruleset          // Built-in rules, plus the ones loaded from files.
+	InclusionChannels   map[string]InclusionChannel // Inclusion settings by channel ID.
+	NudgeCooldown       *nudges.Cooldown
+	Nudges              *nudges.Preferences
+	InclusionStats      *nudges.Stats
+	UserTokens          *oauth.Tokens // Nil if the OAuth flow is disabled.
 
 	Bus EventBus.Bus
 
@@ -29,6 +55,12 @@ type Context struct {
 	staffLookupMap map[string]struct{}
 }
 
+// InclusionChannel are the inclusion settings of a channel, ready to be used.
+type InclusionChannel struct {
+	InclusionConfig
+	Rules *inclusion.Ruleset // Rules of the sets checked in the channel. Nil if all of them are.
+}
+
 func (c *Context) IsStaff(userID string) bool {
 	if c.staffLookupMap == nil {
 		c.staffLookupMap = make(map[string]struct{}, len(c.Config.Staff.Members)) // It is fine to not lock.
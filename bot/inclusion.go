@@ -0,0 +1,280 @@
+package bot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bcneng/candebot/inclusion"
+	"github.com/bcneng/candebot/internal/nudges"
+	"github.com/bcneng/candebot/internal/oauth"
+	"github.com/bcneng/candebot/slackx"
+	"github.com/slack-go/slack"
+)
+
+const (
+	actionInclusionOptOut        = "inclusion_opt_out"
+	actionInclusionSnooze        = "inclusion_snooze"
+	actionInclusionHelpful       = "inclusion_helpful"
+	actionInclusionFalsePositive = "inclusion_false_positive"
+	actionInclusionApply         = "inclusion_apply"
+
+	inclusionStatsTopChannels = 3
+
+	maxSectionTextLength = 2999 // Slack allows up to 3000 characters, the ellipsis included.
+	maxButtonValueLength = 2000
+)
+
+// UnmutedSuggestion drops the matches of the suggestion the user muted the nudges of. The rewrite is done again over
+// the given text. Returns nil if every match is muted.
+func UnmutedSuggestion(botContext Context, user, text string, s *inclusion.Suggestion) *inclusion.Suggestion {
+	if botContext.Nudges == nil || s == nil {
+		return s
+	}
+
+	now := time.Now()
+	var matches []inclusion.Match
+	for _, m := range s.Matches {
+		if !botContext.Nudges.Muted(user, m.Filter.Filter, now) {
+			matches = append(matches, m)
+		}
+	}
+	if len(matches) == 0 {
+		return nil
+	}
+	if len(matches) == len(s.Matches) {
+		return s
+	}
+
+	return &inclusion.Suggestion{Matches: matches, Rewrite: inclusion.Rewrite(text, matches)}
+}
+
+// inclusionRewrite is the message to update when a suggestion is applied.
+type inclusionRewrite struct {
+	Channel   string `json:"channel"`
+	Timestamp string `json:"ts"`
+	Thread    string `json:"thread_ts,omitempty"`
+	Original  string `json:"original"` // Digest of the text the suggestion was made for, to detect later edits.
+	Text      string `json:"text"`
+}
+
+// SendInclusionNudge sends the reply of the suggestion about the given message as an ephemeral message, along with
+// buttons to apply the suggestion, to give feedback and to mute further nudges. The nudge is counted in the stats of
+// the matched rules.
+func SendInclusionNudge(botContext Context, threadTS, channel, user, messageTS, text string, s inclusion.Suggestion) error {
+	reply := s.Reply()
+	rewrite := inclusionRewrite{Channel: channel, Timestamp: messageTS, Thread: threadTS, Original: textDigest(text), Text: s.Rewrite}
+	blocks := inclusionNudgeBlocks(reply, rewrite, s)
+	if err := slackx.SendEphemeral(botContext.Client, threadTS, channel, user, reply, slack.MsgOptionBlocks(blocks...)); err != nil {
+		return err
+	}
+
+	if botContext.InclusionStats != nil {
+		if err := botContext.InclusionStats.RecordMatch(channel, s.Filters()...); err != nil {
+			log.Printf("[ERROR] Recording inclusion stats: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func inclusionNudgeBlocks(reply string, rewrite inclusionRewrite, s inclusion.Suggestion) []slack.Block {
+	var buttons []slack.BlockElement
+
+	// The rewrite goes along with the button, unless it's too long. The reply shows it anyway.
+	if value, _ := json.Marshal(rewrite); rewrite.Text != "" && len(value) <= maxButtonValueLength {
+		apply := slack.NewButtonBlockElement(actionInclusionApply, string(value), slack.NewTextBlockObject(slack.PlainTextType, "Apply suggestion", false, false))
+		apply.Style = slack.StylePrimary
+		buttons = append(buttons, apply)
+	}
+
+	// The matched rules go along with the feedback, so it is counted for all of them.
+	rules, _ := json.Marshal(s.Filters())
+	helpful := slack.NewButtonBlockElement(actionInclusionHelpful, string(rules), slack.NewTextBlockObject(slack.PlainTextType, "Helpful", false, false))
+	falsePositive := slack.NewButtonBlockElement(actionInclusionFalsePositive, string(rules), slack.NewTextBlockObject(slack.PlainTextType, "False positive", false, false))
+	buttons = append(buttons, helpful, falsePositive)
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, truncate(reply, maxSectionTextLength), false, false), nil, nil),
+		slack.NewActionBlock("inclusion_nudge_feedback", buttons...),
+	}
+
+	// Action IDs must be unique within a block, so every term gets its own one.
+	listed := make(map[string]bool, len(s.Matches))
+	for _, m := range s.Matches {
+		if listed[m.Filter.Filter] {
+			continue
+		}
+		listed[m.Filter.Filter] = true
+
+		label := truncate(fmt.Sprintf("Don't remind me about %q for 30 days", strings.ToLower(m.Text)), 72)
+		optOut := slack.NewButtonBlockElement(actionInclusionOptOut, m.Filter.Filter, slack.NewTextBlockObject(slack.PlainTextType, label, false, false))
+		blocks = append(blocks, slack.NewActionBlock(fmt.Sprintf("inclusion_nudge_%d", len(listed)), optOut))
+	}
+
+	snooze := slack.NewButtonBlockElement(actionInclusionSnooze, "", slack.NewTextBlockObject(slack.PlainTextType, "Snooze all nudges", false, false))
+	return append(blocks, slack.NewActionBlock("inclusion_nudge_snooze", snooze))
+}
+
+// handleInclusionNudgeAction mutes the nudges as the user asked, replacing the nudge with a confirmation.
+func handleInclusionNudgeAction(botContext Context, message slack.InteractionCallback, action *slack.BlockAction) {
+	if botContext.Nudges == nil {
+		return
+	}
+
+	user := message.User.ID
+	until := time.Now().Add(nudges.OptOutDuration)
+
+	var err error
+	var msg string
+	switch action.ActionID {
+	case actionInclusionOptOut:
+		err = botContext.Nudges.OptOut(user, action.Value, until)
+		msg = fmt.Sprintf("Got it, I won't remind you about that term until %s.", slackDate(until))
+	case actionInclusionSnooze:
+		err = botContext.Nudges.Snooze(user, until)
+		msg = fmt.Sprintf("Got it, I won't send you any inclusive language nudge until %s.", slackDate(until))
+	}
+	if err != nil {
+		log.Printf("[ERROR] Muting inclusion nudges of %s: %s", user, err)
+		msg = "Sorry, something went wrong and your preference could not be saved. Please try again later."
+	}
+
+	replaceInclusionNudge(botContext, message, msg)
+}
+
+// handleInclusionFeedbackAction counts the feedback of the user for the rules of the nudge, replacing it with a thank you.
+func handleInclusionFeedbackAction(botContext Context, message slack.InteractionCallback, action *slack.BlockAction) {
+	if botContext.InclusionStats == nil {
+		return
+	}
+
+	var rules []string
+	if err := json.Unmarshal([]byte(action.Value), &rules); err != nil {
+		log.Printf("[ERROR] Invalid inclusion nudge rules %q", action.Value)
+		return
+	}
+
+	msg := "Thanks for your feedback! It helps the staff improve the inclusive language rules."
+	if err := botContext.InclusionStats.RecordFeedback(action.ActionID == actionInclusionHelpful, rules...); err != nil {
+		log.Printf("[ERROR] Recording inclusion feedback of %s: %s", message.User.ID, err)
+		msg = "Sorry, something went wrong and your feedback could not be saved. Please try again later."
+	}
+
+	replaceInclusionNudge(botContext, message, msg)
+}
+
+// handleInclusionApplyAction updates the message of the user with the suggestion applied, on their behalf. If the user
+// didn't authorize the bot to do so, the rewritten message is shown ready to copy, along with the link to authorize it.
+// Messages edited since the suggestion was made are left untouched.
+func handleInclusionApplyAction(botContext Context, message slack.InteractionCallback, action *slack.BlockAction) {
+	var rewrite inclusionRewrite
+	if err := json.Unmarshal([]byte(action.Value), &rewrite); err != nil {
+		log.Printf("[ERROR] Invalid inclusion rewrite %q", action.Value)
+		return
+	}
+
+	user := message.User.ID
+	client, err := UserClient(botContext, user)
+	if err == nil {
+		current, _, fetchErr := slackx.FetchMessage(botContext.Client, rewrite.Channel, rewrite.Timestamp, rewrite.Thread, 0)
+		if fetchErr != nil {
+			log.Printf("[ERROR] Fetching the message to apply the inclusion suggestion of %s: %s", user, fetchErr)
+			replaceInclusionNudge(botContext, message, "Sorry, I couldn't find your message to update it. It may have been deleted.")
+			return
+		}
+		if textDigest(current.Text) != rewrite.Original {
+			replaceInclusionNudge(botContext, message, "Your message was edited after the suggestion was made, so I left it as it is.")
+			return
+		}
+
+		if _, _, _, err = client.UpdateMessage(rewrite.Channel, rewrite.Timestamp, slack.MsgOptionText(rewrite.Text, false)); err == nil {
+			replaceInclusionNudge(botContext, message, "Done! Your message has been updated. Thanks for helping to keep this community inclusive.")
+			return
+		}
+
+		log.Printf("[ERROR] Applying inclusion suggestion on behalf of %s: %s", user, err)
+		forgetRevokedToken(botContext, user, err)
+	}
+
+	msg := fmt.Sprintf("Here is your message with the suggestion applied, ready to copy and paste:\n```\n%s\n```", rewrite.Text)
+	if link := AuthorizeLink(botContext, user); link != "" && errors.Is(err, oauth.ErrNoToken) {
+		msg += fmt.Sprintf("\nWant me to edit your messages for you next time? <%s|Authorize %s> to apply suggestions with one click.", link, botContext.Config.Bot.Name)
+	}
+	replaceInclusionNudge(botContext, message, msg)
+}
+
+// textDigest returns a short digest of the text, to tell whether a message changed without keeping its text.
+func textDigest(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:16])
+}
+
+// replaceInclusionNudge replaces the ephemeral nudge the user interacted with by the given text.
+func replaceInclusionNudge(botContext Context, message slack.InteractionCallback, msg string) {
+	if _, _, _, err := botContext.Client.SendMessage(message.Channel.ID, slack.MsgOptionReplaceOriginal(message.ResponseURL), slack.MsgOptionText(msg, false)); err != nil {
+		log.Printf("[ERROR] Replacing inclusion nudge: %s", err)
+	}
+}
+
+// FormatInclusionStats lists the numbers of the given rules, one per line.
+func FormatInclusionStats(stats []nudges.RuleStats) string {
+	if len(stats) == 0 {
+		return "No inclusive language nudges sent yet."
+	}
+
+	lines := make([]string, 0, len(stats))
+	for _, rs := range stats {
+		line := fmt.Sprintf("• `%s`: %d matches", rs.Rule, rs.Matches)
+		if feedback := rs.Helpful + rs.FalsePositives; feedback > 0 {
+			line += fmt.Sprintf(", %d/%d false positives (%.0f%%)", rs.FalsePositives, feedback, rs.FalsePositiveRatio()*100)
+		}
+
+		var channels []string
+		for _, c := range rs.TopChannels(inclusionStatsTopChannels) {
+			channels = append(channels, fmt.Sprintf("<#%s> (%d)", c, rs.Channels[c]))
+		}
+		if len(channels) > 0 {
+			line += ". Top channels: " + strings.Join(channels, ", ")
+		}
+
+		lines = append(lines, line)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// FormatInclusionTest explains how the rules apply to the text, as if it was posted in a channel without language hint:
+// the detected languages, the matched rules and the reply the author would get.
+func FormatInclusionTest(botContext Context, text, languageHint string) string {
+	prose := slackx.Prose(text)
+	languages := strings.Join(inclusion.DetectLanguages(prose), ", ")
+	if languages == "" {
+		languages = "none"
+		if languageHint != "" {
+			languages += ", assuming " + languageHint
+		}
+	}
+
+	s := botContext.InclusionRules.Suggest(prose, "", languageHint)
+	if s == nil {
+		return fmt.Sprintf("Detected languages: %s\nNo rule matches the text.", languages)
+	}
+	s.Rewrite = inclusion.Rewrite(text, s.Matches)
+
+	lines := []string{fmt.Sprintf("Detected languages: %s", languages), "Matches:"}
+	for _, m := range s.Matches {
+		severity := m.Filter.Severity
+		if severity == "" {
+			severity = inclusion.SeverityMedium
+		}
+		lines = append(lines, fmt.Sprintf("• `%s` matched %q at %d-%d (%s, %s severity)", m.Filter.Filter, m.Text, m.Start, m.End, m.Filter.Language, severity))
+	}
+
+	return strings.Join(append(lines, "Reply:", s.Reply()), "\n")
+}
@@ -0,0 +1,226 @@
+package bot
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bcneng/candebot/inclusion"
+	"github.com/bcneng/candebot/internal/crypto"
+	"github.com/bcneng/candebot/internal/nudges"
+	"github.com/bcneng/candebot/internal/oauth"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnmutedSuggestion(t *testing.T) {
+	preferences, err := nudges.NewPreferences("")
+	require.NoError(t, err)
+	botContext := Context{Nudges: preferences}
+
+	rs, err := inclusion.NewRuleset()
+	require.NoError(t, err)
+	text := "hey guys, that's crazy"
+	suggestion := rs.Suggest(text, "", inclusion.LanguageEnglish)
+	require.NotNil(t, suggestion)
+	require.Len(t, suggestion.Matches, 2)
+
+	require.Equal(t, suggestion, UnmutedSuggestion(botContext, "U1", text, suggestion))
+
+	require.NoError(t, preferences.OptOut("U1", "crazy", time.Now().Add(time.Hour)))
+	unmuted := UnmutedSuggestion(botContext, "U1", text, suggestion)
+	require.Len(t, unmuted.Matches, 1)
+	require.Equal(t, "hey y'all, that's crazy", unmuted.Rewrite)
+	require.Len(t, UnmutedSuggestion(botContext, "U2", text, suggestion).Matches, 2)
+
+	require.NoError(t, preferences.Snooze("U1", time.Now().Add(time.Hour)))
+	require.Nil(t, UnmutedSuggestion(botContext, "U1", text, suggestion))
+}
+
+func TestHandleInclusionNudgeAction(t *testing.T) {
+	var replaced map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &replaced)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	preferences, err := nudges.NewPreferences("")
+	require.NoError(t, err)
+	botContext := Context{
+		Client: slack.New("test-token", slack.OptionAPIURL(server.URL+"/")),
+		Nudges: preferences,
+	}
+
+	message := slack.InteractionCallback{
+		User:        slack.User{ID: "U1"},
+		Channel:     slack.Channel{GroupConversation: slack.GroupConversation{Conversation: slack.Conversation{ID: "C1"}}},
+		ResponseURL: server.URL + "/response",
+	}
+
+	handleInclusionNudgeAction(botContext, message, &slack.BlockAction{ActionID: actionInclusionOptOut, Value: "guys"})
+	require.True(t, preferences.Muted("U1", "guys", time.Now()))
+	require.False(t, preferences.Muted("U1", "crazy", time.Now()))
+	require.Equal(t, true, replaced["replace_original"])
+	require.Contains(t, replaced["text"], "I won't remind you about that term until")
+
+	handleInclusionNudgeAction(botContext, message, &slack.BlockAction{ActionID: actionInclusionSnooze})
+	require.True(t, preferences.Snoozed("U1", time.Now()))
+}
+
+func TestInclusionNudgeBlocks(t *testing.T) {
+	rs, err := inclusion.NewRuleset()
+	require.NoError(t, err)
+	suggestion := rs.Suggest("Guys, guys, that's crazy", "", inclusion.LanguageEnglish)
+	require.NotNil(t, suggestion)
+
+	rewrite := inclusionRewrite{Channel: "C1", Timestamp: "1.1", Original: textDigest("Guys, guys, that's crazy"), Text: suggestion.Rewrite}
+	blocks := inclusionNudgeBlocks(suggestion.Reply(), rewrite, *suggestion)
+	require.Len(t, blocks, 5, "the reply, the feedback, one opt-out per rule and the snooze")
+	feedback := blocks[1].(*slack.ActionBlock).Elements.ElementSet
+	require.Len(t, feedback, 3)
+	require.Equal(t, actionInclusionApply, feedback[0].(*slack.ButtonBlockElement).ActionID)
+	require.Equal(t, `{"channel":"C1","ts":"1.1","original":"`+textDigest("Guys, guys, that's crazy")+`","text":"Folks, folks, that's ridiculous"}`, feedback[0].(*slack.ButtonBlockElement).Value)
+	require.Equal(t, `["guys","crazy"]`, feedback[1].(*slack.ButtonBlockElement).Value)
+
+	rewrite.Text = strings.Repeat("a", maxButtonValueLength)
+	blocks = inclusionNudgeBlocks(suggestion.Reply(), rewrite, *suggestion)
+	require.Len(t, blocks[1].(*slack.ActionBlock).Elements.ElementSet, 2, "no apply button if the rewrite doesn't fit")
+
+	optOut := blocks[2].(*slack.ActionBlock).Elements.ElementSet[0].(*slack.ButtonBlockElement)
+	require.Equal(t, actionInclusionOptOut, optOut.ActionID)
+	require.Equal(t, "guys", optOut.Value)
+	require.Equal(t, `Don't remind me about "guys" for 30 days`, optOut.Text.Text)
+}
+
+func TestHandleInclusionFeedbackAction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	stats, err := nudges.NewStats("")
+	require.NoError(t, err)
+	botContext := Context{
+		Client:         slack.New("test-token", slack.OptionAPIURL(server.URL+"/")),
+		InclusionStats: stats,
+	}
+	message := slack.InteractionCallback{User: slack.User{ID: "U1"}, ResponseURL: server.URL + "/response"}
+
+	handleInclusionFeedbackAction(botContext, message, &slack.BlockAction{ActionID: actionInclusionHelpful, Value: `["guys","crazy"]`})
+	handleInclusionFeedbackAction(botContext, message, &slack.BlockAction{ActionID: actionInclusionFalsePositive, Value: `["guys"]`})
+	handleInclusionFeedbackAction(botContext, message, &slack.BlockAction{ActionID: actionInclusionFalsePositive, Value: `invalid`})
+
+	all := stats.All()
+	require.Len(t, all, 2)
+	byRule := map[string]nudges.RuleStats{all[0].Rule: all[0], all[1].Rule: all[1]}
+	require.Equal(t, 1, byRule["guys"].Helpful)
+	require.Equal(t, 1, byRule["guys"].FalsePositives)
+	require.Equal(t, 1, byRule["crazy"].Helpful)
+	require.Zero(t, byRule["crazy"].FalsePositives)
+}
+
+func TestFormatInclusionStats(t *testing.T) {
+	require.Equal(t, "No inclusive language nudges sent yet.", FormatInclusionStats(nil))
+
+	msg := FormatInclusionStats([]nudges.RuleStats{
+		{Rule: "guys", Matches: 12, Helpful: 3, FalsePositives: 1, Channels: map[string]int{"C1": 2, "C2": 7, "C3": 1, "C4": 2}},
+		{Rule: "crazy", Matches: 1, Channels: map[string]int{"C1": 1}},
+	})
+	require.Equal(t, "• `guys`: 12 matches, 1/4 false positives (25%). Top channels: <#C2> (7), <#C1> (2), <#C4> (2)\n"+
+		"• `crazy`: 1 matches. Top channels: <#C1> (1)", msg)
+}
+
+func TestHandleInclusionApplyAction(t *testing.T) {
+	var updated, replaced string
+	current := "hey guys"
+	updateResponse := `{"ok": true, "channel": "C1", "ts": "1.1"}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path == "/conversations.history" {
+			history, _ := json.Marshal(map[string]interface{}{"ok": true, "messages": []map[string]string{{"type": "message", "ts": "1.1", "text": current}}})
+			_, _ = w.Write(history)
+			return
+		}
+		if r.URL.Path == "/chat.update" {
+			_ = r.ParseForm()
+			updated = r.PostForm.Get("text")
+			_, _ = w.Write([]byte(updateResponse))
+			return
+		}
+
+		var body map[string]interface{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		replaced, _ = body["text"].(string)
+		_, _ = w.Write([]byte(`{"ok": true}`))
+	}))
+	defer server.Close()
+
+	defer func(original func(string) *slack.Client) { newUserClient = original }(newUserClient)
+	newUserClient = func(token string) *slack.Client {
+		return slack.New(token, slack.OptionAPIURL(server.URL+"/"))
+	}
+
+	botContext := Context{
+		Client: slack.New("test-token", slack.OptionAPIURL(server.URL+"/")),
+		Config: Config{
+			Bot:   ConfigBot{Name: "candebot", Server: ConfigBotServer{SigningSecret: "secret"}},
+			OAuth: ConfigOAuth{ClientID: "123.456", RedirectURL: "https://bot.example.com/oauth/callback"},
+		},
+	}
+	message := slack.InteractionCallback{User: slack.User{ID: "U1"}, ResponseURL: server.URL + "/response"}
+	value, err := json.Marshal(inclusionRewrite{Channel: "C1", Timestamp: "1.1", Original: textDigest("hey guys"), Text: "hey y'all"})
+	require.NoError(t, err)
+	action := &slack.BlockAction{ActionID: actionInclusionApply, Value: string(value)}
+
+	handleInclusionApplyAction(botContext, message, action)
+	require.Empty(t, updated)
+	require.Contains(t, replaced, "ready to copy and paste:\n```\nhey y'all\n```")
+	require.NotContains(t, replaced, "Authorize", "no authorization offered when OAuth is disabled")
+
+	cipher, err := crypto.NewCipher("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	require.NoError(t, err)
+	botContext.UserTokens, err = oauth.NewTokens("", cipher)
+	require.NoError(t, err)
+
+	handleInclusionApplyAction(botContext, message, action)
+	require.Empty(t, updated)
+	require.Contains(t, replaced, "ready to copy and paste")
+	require.Contains(t, replaced, "<"+oauth.AuthorizeURL+"?client_id=123.456")
+
+	require.NoError(t, botContext.UserTokens.Set("U1", "xoxp-token"))
+	handleInclusionApplyAction(botContext, message, action)
+	require.Equal(t, "hey y'all", updated)
+	require.Contains(t, replaced, "Done! Your message has been updated.")
+
+	updated = ""
+	current = "hey guys, see you tomorrow"
+	handleInclusionApplyAction(botContext, message, action)
+	require.Empty(t, updated, "edits made after the suggestion must not be overwritten")
+	require.Contains(t, replaced, "edited after the suggestion was made")
+	current = "hey guys"
+
+	updateResponse = `{"ok": false, "error": "token_revoked"}`
+	handleInclusionApplyAction(botContext, message, action)
+	require.Contains(t, replaced, "ready to copy and paste")
+	_, err = botContext.UserTokens.Get("U1")
+	require.ErrorIs(t, err, oauth.ErrNoToken, "revoked tokens are forgotten")
+}
+
+func TestFormatInclusionTest(t *testing.T) {
+	botContext, err := NewCLIContext(Config{})
+	require.NoError(t, err)
+
+	msg := FormatInclusionTest(botContext, "hey guys, see `make guys`", "")
+	require.Contains(t, msg, "Detected languages: none\nMatches:\n• `hey guys` matched \"hey guys\" at 0-8 (en, medium severity)\nReply:\n")
+	require.Contains(t, msg, "hey y'all, see `make guys`")
+
+	require.Equal(t, "Detected languages: none, assuming ca\nNo rule matches the text.", FormatInclusionTest(botContext, "noise", "ca"))
+}
@@ -2,6 +2,7 @@ package bot
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -10,13 +11,14 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/avast/retry-go/v4"
+	"github.com/bcneng/candebot/internal/audit"
+	"github.com/bcneng/candebot/internal/jobs"
 	"github.com/bcneng/candebot/slackx"
 	"github.com/newrelic/newrelic-telemetry-sdk-go/telemetry"
 	"github.com/slack-go/slack"
-	"golang.org/x/text/cases"
-	"golang.org/x/text/language"
 )
 
 func interactAPIHandler(botContext Context) http.HandlerFunc {
@@ -47,10 +49,10 @@ func interactAPIHandler(botContext Context) http.HandlerFunc {
 		case slack.InteractionTypeMessageAction:
 			switch message.CallbackID {
 			case "report_message":
-				dialog := generateReportMessageDialog()
-				dialog.State = slackx.LinkToMessage(message.Channel.ID, message.MessageTs) // persist the message link across submission
-				if err := botContext.Client.OpenDialog(message.TriggerID, dialog); err != nil {
-					log.Println(err)
+				modal := generateReportMessageModal(botContext.ReportCipher != nil)
+				modal.PrivateMetadata = reportMessageMetadata(message.Channel.ID, message.MessageTs, message.Message.ThreadTimestamp, message.Message.User) // persist the reported message across submission
+				if resp, err := botContext.Client.OpenView(message.TriggerID, modal); err != nil {
+					logModalError(err, resp)
 				}
 			case "delete_job_post":
 				modal := generateDeleteJobPostModal()
@@ -76,6 +78,15 @@ func interactAPIHandler(botContext Context) http.HandlerFunc {
 			}
 		case slack.InteractionTypeViewSubmission:
 			switch message.View.CallbackID {
+			case actionJobPostReject, actionJobPostRequestChanges:
+				handleJobPostReviewReasonSubmission(botContext, w, message)
+			case "report_message":
+				// Answering right away, as Slack expects submissions to be answered within 3 seconds.
+				w.Header().Set("Content-Type", "application/json")
+				_ = json.NewEncoder(w).Encode(slack.NewClearViewSubmissionResponse())
+				go handleReportSubmission(botContext, message)
+			case actionReportResolve, actionReportWarnUser:
+				handleReportModalSubmission(botContext, w, message)
 			case "delete_job_post":
 				// We early set the Content-Type header for any response. This is important.
 				w.Header().Set("Content-Type", "application/json")
@@ -111,6 +122,12 @@ func interactAPIHandler(botContext Context) http.HandlerFunc {
 
 				log.Println("Job post message deleted successfully", message.View.PrivateMetadata)
 
+				if botContext.JobPosts != nil {
+					if _, err := botContext.JobPosts.MarkDeleted(channelID, messageTS, time.Now()); err != nil {
+						log.Printf("[ERROR] Failed to mark job post %s as deleted: %s", messageTS, err)
+					}
+				}
+
 				// Sending metrics
 				botContext.Harvester.RecordMetric(telemetry.Count{
 					Name:      fmt.Sprintf("%s_%s", strings.ToLower(botContext.Config.Bot.Name), "job_post.deleted"),
@@ -155,11 +172,20 @@ func interactAPIHandler(botContext Context) http.HandlerFunc {
 
 				go func() {
 					deleted, ok := deleteThreadMessages(botContext, threadMessages, channelID)
+					var deletionErr error
 					if !ok {
 						log.Println("Thread deletion finished with errors (see logs)", message.View.PrivateMetadata)
+						deletionErr = fmt.Errorf("%d of %d messages could not be deleted", len(threadMessages)-deleted, len(threadMessages))
 					} else {
 						log.Printf("Thread deletion finished successfully, %d messages where removed, including parent message: %s", deleted, message.View.PrivateMetadata)
 					}
+					Audit(botContext, audit.Entry{
+						Actor:    message.User.ID,
+						Action:   "delete_thread",
+						Target:   slackx.LinkToMessage(channelID, messageTS),
+						Params:   map[string]string{"deleted": strconv.Itoa(deleted)},
+						Severity: audit.SeverityHigh,
+					}, deletionErr)
 
 					//Sending metrics
 					botContext.Harvester.RecordMetric(telemetry.Count{
@@ -178,90 +204,28 @@ func interactAPIHandler(botContext Context) http.HandlerFunc {
 
 		case slack.InteractionTypeDialogSubmission:
 			switch message.CallbackID {
-			case "report_message":
-				msg := fmt.Sprintf("<@%s> sent a message report:\n- *Reason*: %s\n- *Feeling Scale*: %s of 5\n%s",
-					message.User.Name,
-					message.Submission["reason"],
-					message.Submission["scale"],
-					sanitizeReportState(message.State),
-				)
-				_ = slackx.Send(botContext.Client, "", botContext.Config.Channels.Staff, msg, false)
-
-				// Sending metrics
-				botContext.Harvester.RecordMetric(telemetry.Count{
-					Name: fmt.Sprintf("%s.%s", strings.ToLower(botContext.Config.Bot.Name), "report_message.received"),
-					Attributes: map[string]interface{}{
-						"scale": message.Submission["scale"],
-					},
-					Value:     1,
-					Timestamp: time.Now(),
-				})
 			case "job_submission":
-				link, maxSalary, minSalary, validationErrors := validateSubmission(message.Submission["job_link"], message.Submission["max_salary"], message.Submission["min_salary"])
-				if link != nil && link.Query().Get("utm_source") == "" {
-					// Add utm_source to the job link only if doesn't have one already
-					query := link.Query()
-					query.Add("utm_source", "bcneng")
-					link.RawQuery = query.Encode()
-					message.Submission["job_link"] = link.String()
-				}
-
-				if len(validationErrors) > 0 {
-					var errs []slack.DialogInputValidationError
-					for f, err := range validationErrors {
-						errs = append(errs, slack.DialogInputValidationError{
-							Name:  f,
-							Error: err,
-						})
-					}
-
-					_ = json.NewEncoder(w).Encode(slack.DialogInputValidationErrors{
-						Errors: errs,
-					})
-
-					return
-				}
-
-				minSalaryStr := fmt.Sprintf("%dK", minSalary)
-				if minSalary == -1 {
-					minSalaryStr = ""
+				handleJobSubmission(botContext, w, r, message)
+			}
+		case slack.InteractionTypeBlockActions:
+			for _, action := range message.ActionCallback.BlockActions {
+				switch action.ActionID {
+				case actionJobPostApprove, actionJobPostReject, actionJobPostRequestChanges:
+					handleJobPostReviewAction(botContext, message, action)
+				case actionReportTake, actionReportResolve, actionReportDeleteMessage, actionReportWarnUser:
+					handleReportAction(botContext, message, action)
+				case actionInclusionOptOut, actionInclusionSnooze:
+					handleInclusionNudgeAction(botContext, message, action)
+				case actionInclusionHelpful, actionInclusionFalsePositive:
+					handleInclusionFeedbackAction(botContext, message, action)
+				case actionInclusionApply:
+					handleInclusionApplyAction(botContext, message, action)
 				}
-
-				msg := fmt.Sprintf(":computer: %s @ %s - :moneybag: %s - %dK %s - :round_pushpin: %s - :lower_left_fountain_pen: %s - :link: <%s|Link> - :raised_hands: More info DM <@%s>",
-					message.Submission["role"],
-					message.Submission["company"],
-					minSalaryStr,
-					maxSalary,
-					message.Submission["currency"],
-					message.Submission["location"],
-					message.Submission["publisher"],
-					message.Submission["job_link"],
-					message.User.Name,
-				)
-				_ = slackx.Send(botContext.Client, "", botContext.Config.Channels.Jobs, msg, false, slack.MsgOptionDisableLinkUnfurl())
-
-				// Sending metrics
-				botContext.Harvester.RecordMetric(telemetry.Count{
-					Name: fmt.Sprintf("%s.%s", strings.ToLower(botContext.Config.Bot.Name), "job_post.published"),
-					Attributes: map[string]interface{}{
-						"role":      cases.Title(language.English).String(strings.ToLower(message.Submission["role"])),
-						"company":   cases.Title(language.English).String(strings.ToLower(message.Submission["company"])),
-						"minSalary": minSalary,
-						"maxSalary": maxSalary,
-						"currency":  message.Submission["currency"],
-						"location":  message.Submission["location"],
-						"publisher": message.Submission["publisher"],
-						"job_link":  message.Submission["job_link"],
-						"user":      message.User.Name,
-					},
-					Value:     1,
-					Timestamp: time.Now(),
-				})
 			}
 		case slack.InteractionTypeShortcut:
 			switch message.CallbackID {
 			case "submit_job":
-				if err := botContext.Client.OpenDialog(message.TriggerID, generateSubmitJobFormDialog()); err != nil {
+				if err := botContext.Client.OpenDialog(message.TriggerID, generateSubmitJobFormDialog(botContext.Config.Jobs)); err != nil {
 					log.Println(err)
 				}
 			case "suggest_channel":
@@ -316,6 +280,97 @@ func deleteThreadMessages(botContext Context, threadMessages []slack.Message, ch
 	return deleted, !errored
 }
 
+// findDuplicateJobPost looks for a recently published job post, or one waiting for staff review, that is a
+// near-duplicate of the given one. Staff members are allowed to publish duplicates.
+func findDuplicateJobPost(botContext Context, post jobs.Post) (jobs.Post, bool) {
+	window := intValue(botContext.Config.Jobs.DuplicateWindowDays)
+	if window <= 0 || botContext.IsStaff(post.Author) {
+		return jobs.Post{}, false
+	}
+
+	if botContext.JobPosts != nil {
+		if duplicate, found := botContext.JobPosts.FindDuplicate(post, post.PublishedAt.AddDate(0, 0, -window)); found {
+			return duplicate, true
+		}
+	}
+	if botContext.JobQueue != nil {
+		if submission, found := botContext.JobQueue.FindDuplicate(post); found {
+			return submission.Post, true
+		}
+	}
+
+	return jobs.Post{}, false
+}
+
+func duplicateJobPostError(duplicate jobs.Post) string {
+	if duplicate.Permalink == "" {
+		return "This offer was already submitted and is waiting for staff review."
+	}
+
+	return fmt.Sprintf("This offer was already posted on %s: %s", duplicate.PublishedAt.Format("2006-01-02"), duplicate.Permalink)
+}
+
+// linkValidationError returns a friendly validation error for a job link that failed the check.
+func linkValidationError(err error) string {
+	var statusErr *jobs.LinkStatusError
+	if errors.As(err, &statusErr) {
+		return fmt.Sprintf("The link to the job spec responded with an error (HTTP %d). Make sure it is public and the offer is still open.", statusErr.StatusCode)
+	}
+
+	return "The link to the job spec could not be reached. Make sure it is correct and publicly accessible."
+}
+
+// validateSalaryFloor checks the salary is not below the minimum configured for its currency.
+// Returns the field to report the error in, and the error message. The message is empty if valid.
+func validateSalaryFloor(salary jobs.Salary, floors map[string]int) (string, string) {
+	floor, below := salary.BelowFloor(floors)
+	if !below {
+		return "", ""
+	}
+
+	field := "max_salary"
+	if salary.Min >= 0 {
+		field = "min_salary"
+	}
+
+	return field, fmt.Sprintf("The salary is below the minimum allowed for %s offers (%dK %s).", salary.Currency, floor, salary.Currency)
+}
+
+// formatSalaryRange formats a salary range in thousands. E.g. 60K - 90K.
+func formatSalaryRange(salary jobs.Salary) string {
+	if salary.Min < 0 {
+		return fmt.Sprintf("%dK", salary.Max)
+	}
+
+	return fmt.Sprintf("%dK - %dK", salary.Min, salary.Max)
+}
+
+// validateSubmissionOptions checks the submitted options and text lengths are the ones currently offered in the form,
+// as the config could have changed since the form was opened. Produces a map of field name to error message.
+func validateSubmissionOptions(conf ConfigJobs, submission map[string]string) map[string]string {
+	validationErrors := make(map[string]string)
+	if _, ok := findJobsOption(conf.Locations, submission["location"]); !ok {
+		validationErrors["location"] = "The selected location is no longer available. Please choose another one."
+	}
+
+	if _, ok := findJobsOption(conf.Publishers, submission["publisher"]); !ok {
+		validationErrors["publisher"] = "The selected publisher type is no longer available. Please choose another one."
+	}
+
+	if !conf.HasCurrency(submission["currency"]) {
+		validationErrors["currency"] = "The selected currency is no longer available. Please choose another one."
+	}
+
+	lengths := map[string]ConfigJobsFieldLength{"role": conf.Role, "company": conf.Company}
+	for field, l := range lengths {
+		if n := utf8.RuneCountInString(strings.TrimSpace(submission[field])); n < l.MinLength || n > l.MaxLength {
+			validationErrors[field] = fmt.Sprintf("This field should be between %d and %d characters long.", l.MinLength, l.MaxLength)
+		}
+	}
+
+	return validationErrors
+}
+
 // validateSubmission runs validations over the submitted salary range and job offer link. Produces a list of errors if any.
 //
 // Arguments are the strings as read from the submissions (no previous transform/parsing/filter)
@@ -360,22 +415,32 @@ func validateSubmission(messageJobLink, messageMaxSalary, messageMinSalary strin
 	return link, maxSalary, minSalary, validationErrors
 }
 
-func generateSubmitJobFormDialog() slack.Dialog {
+func generateSubmitJobFormDialog(conf ConfigJobs) slack.Dialog {
 	// Make new dialog components and open a dialog.
 	// Component-Text
 	roleInput := slack.NewTextInput("role", "Role", "")
 	roleInput.Placeholder = "Software Engineer"
 	roleInput.Hint = "Links or special characters are not allowed."
-	roleInput.MaxLength = 50
-	roleInput.MinLength = 2
+	roleInput.MaxLength = conf.Role.MaxLength
+	roleInput.MinLength = conf.Role.MinLength
 
 	companyInput := slack.NewTextInput("company", "Company", "")
 	companyInput.Placeholder = "BcnEng"
-	companyInput.Hint = "It MUST be the final company name, no name of agencies/intermediaries allowed. Links or special characters are not allowed"
-	companyInput.MaxLength = 20
-	companyInput.MinLength = 2
-
-	salaryCurrencyOptions := []slack.DialogSelectOption{{"EUR", "EUR"}, {"USD", "USD"}, {"GBP", "GBP"}, {"CHF", "CHF"}} // nolint: govet
+	companyInput.Hint = "The hiring company, or your agency name if you are an agency (then fill in the final client below). Links or special characters are not allowed"
+	companyInput.MaxLength = conf.Company.MaxLength
+	companyInput.MinLength = conf.Company.MinLength
+
+	clientInput := slack.NewTextInput("client", "Final client (agencies only)", "")
+	clientInput.Optional = true
+	clientInput.Placeholder = "BcnEng"
+	clientInput.Hint = "Agencies MUST name the final company they are hiring for."
+	clientInput.MaxLength = conf.Company.MaxLength
+	clientInput.MinLength = conf.Company.MinLength
+
+	salaryCurrencyOptions := make([]slack.DialogSelectOption, 0, len(conf.Currencies))
+	for _, currency := range conf.Currencies {
+		salaryCurrencyOptions = append(salaryCurrencyOptions, slack.DialogSelectOption{Label: currency, Value: currency})
+	}
 	salaryCurrencyInput := slack.NewStaticSelectDialogInput("currency", "Currency", salaryCurrencyOptions)
 	salaryCurrencyInput.Optional = false
 	salaryCurrencyInput.Hint = "Choose the salary currency from the dropdown"
@@ -401,33 +466,16 @@ func generateSubmitJobFormDialog() slack.Dialog {
 	linkInput.Subtype = slack.InputSubtypeEmail
 
 	// Component-Select menu
-	options := []slack.DialogSelectOption{
-		{
-			Label: "Barcelona",
-			Value: "Barcelona",
-		},
-		{
-			Label: "Barcelona/Remote",
-			Value: "Barcelona/Remote",
-		},
-		{
-			Label: "Remote (temporary because of COVID)",
-			Value: "Remote (COVID)",
-		},
-		{
-			Label: "Remote",
-			Value: "Remote",
-		},
-	}
-	locationInput := slack.NewStaticSelectDialogInput("location", "Location - Select", options)
+	locationInput := slack.NewStaticSelectDialogInput("location", "Location - Select", dialogSelectOptions(conf.Locations))
 	locationInput.Optional = false
 
-	publisherInput := buildPublisherInput()
+	publisherInput := buildPublisherInput(conf.Publishers)
 
 	// Open a dialog
 	elements := []slack.DialogElement{
 		roleInput,
 		companyInput,
+		clientInput,
 		salaryMinInput,
 		salaryMaxInput,
 		salaryCurrencyInput,
@@ -493,55 +541,54 @@ func userNotAllowedModal() slack.ModalViewRequest {
 	}
 }
 
-func generateReportMessageDialog() slack.Dialog {
-	reasonInput := slack.NewTextInput("reason", "Reason", "")
-	reasonInput.Placeholder = "Violates BcnEng's COC by using a violent language"
-	reasonInput.Hint = "Explain the reason of this report."
+func generateReportMessageModal(anonymousAllowed bool) slack.ModalViewRequest {
+	reasonInput := slack.NewPlainTextInputBlockElement(slack.NewTextBlockObject(slack.PlainTextType, "Violates BcnEng's COC by using a violent language", false, false), "reason")
 	reasonInput.MinLength = 5
-	reasonInput.Optional = false
+	reasonBlock := slack.NewInputBlock("reason", slack.NewTextBlockObject(slack.PlainTextType, "Reason", false, false), slack.NewTextBlockObject(slack.PlainTextType, "Explain the reason of this report.", false, false), reasonInput)
 
-	feelingScale := slack.NewStaticSelectDialogInput("scale", "How hurtful their words felt to you?", []slack.DialogSelectOption{
-		{Label: "1", Value: "1"},
-		{Label: "2", Value: "2"},
-		{Label: "3", Value: "3"},
-		{Label: "4", Value: "4"},
-		{Label: "5", Value: "5"},
-	})
-	feelingScale.Hint = "5 point scale ranging starting from 1 (minimum) to 5 (extremely), where a greater score corresponds to a more hurtful feeling"
-
-	elements := []slack.DialogElement{
-		reasonInput,
-		feelingScale,
+	scaleOptions := make([]*slack.OptionBlockObject, 0, 5)
+	for i := 1; i <= 5; i++ {
+		value := strconv.Itoa(i)
+		scaleOptions = append(scaleOptions, slack.NewOptionBlockObject(value, slack.NewTextBlockObject(slack.PlainTextType, value, false, false), nil))
 	}
-	return slack.Dialog{
-		CallbackID:  "report_message",
-		Title:       "Report message",
-		SubmitLabel: "Report",
-		Elements:    elements,
+	scaleInput := slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, slack.NewTextBlockObject(slack.PlainTextType, "Choose an option", false, false), "scale", scaleOptions...)
+	scaleBlock := slack.NewInputBlock("scale", slack.NewTextBlockObject(slack.PlainTextType, "How hurtful their words felt to you?", false, false), slack.NewTextBlockObject(slack.PlainTextType, "5 point scale ranging starting from 1 (minimum) to 5 (extremely), where a greater score corresponds to a more hurtful feeling", false, false), scaleInput)
+
+	blocks := []slack.Block{reasonBlock, scaleBlock}
+	if anonymousAllowed {
+		anonymousOptionText := slack.NewTextBlockObject(slack.PlainTextType, "Report anonymously", false, false)
+		anonymousDescriptionText := slack.NewTextBlockObject(slack.PlainTextType, "Staff members won't see who sent the report. Your identity is stored encrypted, and only revealed to follow up on abuse.", false, false)
+		checkbox := slack.NewCheckboxGroupsBlockElement("anonymous", slack.NewOptionBlockObject("anonymous", anonymousOptionText, anonymousDescriptionText))
+		anonymousBlock := slack.NewInputBlock("anonymous", slack.NewTextBlockObject(slack.PlainTextType, "Privacy", false, false), nil, checkbox)
+		anonymousBlock.Optional = true
+		blocks = append(blocks, anonymousBlock)
 	}
-}
 
-func buildPublisherInput() *slack.DialogInputSelect {
-	publisherOptions := []slack.DialogSelectOption{
-		{
-			Label: "Employer",
-			Value: "Employer",
-		},
-		{
-			Label: "Agency",
-			Value: "Agency",
-		},
-		{
-			Label: "Referral",
-			Value: "Referral",
-		},
+	return slack.ModalViewRequest{
+		Type:       slack.VTModal,
+		Title:      slack.NewTextBlockObject(slack.PlainTextType, "Report message", false, false),
+		Blocks:     slack.Blocks{BlockSet: blocks},
+		Submit:     slack.NewTextBlockObject(slack.PlainTextType, "Report", false, false),
+		CallbackID: "report_message",
 	}
-	publisherInput := slack.NewStaticSelectDialogInput("publisher", "Published by", publisherOptions)
+}
+
+func buildPublisherInput(options []ConfigJobsOption) *slack.DialogInputSelect {
+	publisherInput := slack.NewStaticSelectDialogInput("publisher", "Published by", dialogSelectOptions(options))
 	publisherInput.Optional = false
 
 	return publisherInput
 }
 
+func dialogSelectOptions(options []ConfigJobsOption) []slack.DialogSelectOption {
+	selectOptions := make([]slack.DialogSelectOption, 0, len(options))
+	for _, o := range options {
+		selectOptions = append(selectOptions, slack.DialogSelectOption{Label: o.Label, Value: o.Value})
+	}
+
+	return selectOptions
+}
+
 func suggestChannelModal() slack.ModalViewRequest {
 	text := "To suggest a new channel, edit the channels file and submit a Pull Request:\n\n" +
 		"<https://github.com/bcneng/website/edit/main/data/channels.json|:pencil: Edit channels.json on GitHub>"
@@ -2,7 +2,10 @@ package bot
 
 import (
 	"testing"
+	"time"
 
+	"github.com/bcneng/candebot/internal/jobs"
+	"github.com/slack-go/slack"
 	"github.com/stretchr/testify/require"
 )
 
@@ -80,6 +83,139 @@ func TestJobSubmission(t *testing.T) {
 
 }
 
+func TestFindDuplicateJobPost(t *testing.T) {
+	store, err := jobs.NewStore("")
+	require.NoError(t, err)
+
+	published := jobs.Post{
+		Channel:     "C123",
+		Timestamp:   "1700000000.000100",
+		Permalink:   "https://bcneng.slack.com/archives/C123/p1700000000000100",
+		Author:      "U111",
+		Role:        "Backend Engineer",
+		Company:     "Acme",
+		Link:        "https://acme.com/jobs/1",
+		PublishedAt: time.Now().Add(-24 * time.Hour),
+	}
+	require.NoError(t, store.Add(published))
+
+	botContext := Context{
+		Config: Config{
+			Staff: ConfigStaff{Members: []string{"USTAFF"}},
+			Jobs:  ConfigJobs{DuplicateWindowDays: intPtr(7)},
+		},
+		JobPosts: store,
+	}
+
+	candidate := published
+	candidate.Author = "U222"
+	candidate.PublishedAt = time.Now()
+
+	t.Run("duplicates are detected", func(t *testing.T) {
+		duplicate, found := findDuplicateJobPost(botContext, candidate)
+		require.True(t, found)
+		requireHasError(t, duplicateJobPostError(duplicate))
+		require.Contains(t, duplicateJobPostError(duplicate), published.Permalink)
+	})
+
+	t.Run("staff members are allowed to post duplicates", func(t *testing.T) {
+		staffCandidate := candidate
+		staffCandidate.Author = "USTAFF"
+		_, found := findDuplicateJobPost(botContext, staffCandidate)
+		require.False(t, found)
+	})
+
+	t.Run("a zero window disables the detection", func(t *testing.T) {
+		disabled := botContext
+		disabled.Config.Jobs.DuplicateWindowDays = intPtr(0)
+		_, found := findDuplicateJobPost(disabled, candidate)
+		require.False(t, found)
+	})
+
+	t.Run("submissions pending review are detected", func(t *testing.T) {
+		queue, err := jobs.NewQueue("")
+		require.NoError(t, err)
+		pending := jobs.Post{Author: "U333", Role: "Frontend Engineer", Company: "Acme S.L.", Link: "https://acme.com/jobs/2"}
+		_, err = queue.Enqueue(pending, time.Now())
+		require.NoError(t, err)
+
+		withQueue := botContext
+		withQueue.JobQueue = queue
+		resubmitted := pending
+		resubmitted.Company = "Acme"
+		resubmitted.PublishedAt = time.Now()
+		duplicate, found := findDuplicateJobPost(withQueue, resubmitted)
+		require.True(t, found)
+		require.Contains(t, duplicateJobPostError(duplicate), "waiting for staff review")
+	})
+}
+
+func TestLinkValidationError(t *testing.T) {
+	requireHasError(t, linkValidationError(&jobs.LinkStatusError{StatusCode: 404}))
+	require.Contains(t, linkValidationError(&jobs.LinkStatusError{StatusCode: 404}), "404")
+	requireHasError(t, linkValidationError(jobs.ErrLinkUnreachable))
+}
+
+func TestValidateSalaryFloor(t *testing.T) {
+	floors := map[string]int{"EUR": 20}
+
+	field, err := validateSalaryFloor(jobs.Salary{Min: 10, Max: 20, Currency: "EUR"}, floors)
+	require.Equal(t, "min_salary", field)
+	requireHasError(t, err)
+
+	field, err = validateSalaryFloor(jobs.Salary{Min: -1, Max: 15, Currency: "EUR"}, floors)
+	require.Equal(t, "max_salary", field)
+	requireHasError(t, err)
+
+	_, err = validateSalaryFloor(jobs.Salary{Min: 30, Max: 40, Currency: "EUR"}, floors)
+	require.Empty(t, err)
+}
+
+func TestFormatSalaryRange(t *testing.T) {
+	require.Equal(t, "60K - 90K", formatSalaryRange(jobs.Salary{Min: 60, Max: 90}))
+	require.Equal(t, "90K", formatSalaryRange(jobs.Salary{Min: -1, Max: 90}))
+}
+
+func TestValidateSubmissionOptions(t *testing.T) {
+	var conf ConfigJobs
+	conf.applyDefaults()
+
+	valid := map[string]string{
+		"role":      "Software Engineer",
+		"company":   "BcnEng",
+		"location":  "Barcelona",
+		"publisher": "Employer",
+		"currency":  "EUR",
+	}
+	require.Empty(t, validateSubmissionOptions(conf, valid))
+
+	invalid := map[string]string{
+		"role":      "A",
+		"company":   "A company name way longer than allowed",
+		"location":  "Remote (COVID)",
+		"publisher": "Recruiter",
+		"currency":  "JPY",
+	}
+	validationErrors := validateSubmissionOptions(conf, invalid)
+	for field := range invalid {
+		requireHasError(t, validationErrors[field])
+	}
+}
+
+func TestGenerateSubmitJobFormDialog(t *testing.T) {
+	conf := ConfigJobs{Locations: []ConfigJobsOption{{Label: "Anywhere", Value: "Anywhere"}}}
+	conf.applyDefaults()
+
+	dialog := generateSubmitJobFormDialog(conf)
+	for _, e := range dialog.Elements {
+		if s, ok := e.(*slack.DialogInputSelect); ok && s.Name == "location" {
+			require.Equal(t, []slack.DialogSelectOption{{Label: "Anywhere", Value: "Anywhere"}}, s.Options)
+			return
+		}
+	}
+	t.Fatal("location input not found")
+}
+
 func TestMessageSanitizing(t *testing.T) {
 	t.Run("urls cannot contain double scaped backlashes", func(t *testing.T) {
 		url := "https:\\/\\/bcneng.slack.com\\/archives"
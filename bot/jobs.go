@@ -0,0 +1,402 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bcneng/candebot/internal/jobs"
+	"github.com/bcneng/candebot/slackx"
+	"github.com/newrelic/newrelic-telemetry-sdk-go/telemetry"
+	"github.com/slack-go/slack"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+)
+
+// Block Kit action IDs of the job post review message sent to staff.
+// Reject and request changes IDs are also used as callback IDs of the modal asking for the reason.
+const (
+	actionJobPostApprove        = "job_post_approve"
+	actionJobPostReject         = "job_post_reject"
+	actionJobPostRequestChanges = "job_post_request_changes"
+)
+
+func handleJobSubmission(botContext Context, w http.ResponseWriter, r *http.Request, message slack.InteractionCallback) {
+	link, maxSalary, minSalary, validationErrors := validateSubmission(message.Submission["job_link"], message.Submission["max_salary"], message.Submission["min_salary"])
+	for f, err := range validateSubmissionOptions(botContext.Config.Jobs, message.Submission) {
+		validationErrors[f] = err
+	}
+
+	if link != nil && botContext.JobLinkChecker != nil {
+		resolved, err := botContext.JobLinkChecker.Resolve(r.Context(), link)
+		if err != nil {
+			log.Printf("Job link %q failed the check: %s", link, err)
+			validationErrors["job_link"] = linkValidationError(err)
+		} else {
+			link = resolved
+		}
+	}
+
+	if link != nil {
+		// Strip tracking params and add our utm_source (unless it has one already)
+		message.Submission["job_link"] = jobs.TagLink(link).String()
+	}
+
+	salary := jobs.Salary{Min: minSalary, Max: maxSalary, Currency: message.Submission["currency"]}
+	if field, err := validateSalaryFloor(salary, botContext.Config.Jobs.MinSalary); err != "" && validationErrors["min_salary"] == "" && validationErrors["max_salary"] == "" {
+		validationErrors[field] = err
+	}
+
+	post := jobs.Post{
+		Channel:     botContext.Config.Channels.Jobs,
+		Author:      message.User.ID,
+		AuthorName:  message.User.Name,
+		Role:        message.Submission["role"],
+		Company:     message.Submission["company"],
+		Client:      strings.TrimSpace(message.Submission["client"]),
+		Location:    message.Submission["location"],
+		Publisher:   message.Submission["publisher"],
+		Currency:    message.Submission["currency"],
+		MinSalary:   minSalary,
+		MaxSalary:   maxSalary,
+		Link:        message.Submission["job_link"],
+		PublishedAt: time.Now(),
+	}
+
+	verdict, violations := botContext.JobPolicy.Evaluate(post)
+	for f, err := range violations {
+		validationErrors[f] = err
+	}
+
+	if len(validationErrors) > 0 {
+		writeDialogValidationErrors(w, validationErrors)
+		return
+	}
+
+	if duplicate, found := findDuplicateJobPost(botContext, post); found {
+		writeDialogValidationErrors(w, map[string]string{"job_link": duplicateJobPostError(duplicate)})
+		return
+	}
+
+	if salaryEUR, ok := botContext.ExchangeRates.ToEUR(salary); ok {
+		post.MinSalaryEUR = salaryEUR.Min
+		post.MaxSalaryEUR = salaryEUR.Max
+	}
+
+	if verdict == jobs.Review && botContext.JobQueue != nil {
+		if err := requestJobPostReview(botContext, post); err != nil {
+			log.Printf("[ERROR] Failed to send job post to review: %s", err)
+			writeDialogValidationErrors(w, map[string]string{"job_link": "Your job post could not be sent to review. Please try again later."})
+		}
+		return
+	}
+
+	if err := publishJobPost(botContext, post); err != nil {
+		log.Printf("[ERROR] Failed to publish job post: %s", err)
+		writeDialogValidationErrors(w, map[string]string{"job_link": "Your job post could not be published. Please try again later."})
+	}
+}
+
+func writeDialogValidationErrors(w http.ResponseWriter, validationErrors map[string]string) {
+	errs := make([]slack.DialogInputValidationError, 0, len(validationErrors))
+	for f, err := range validationErrors {
+		errs = append(errs, slack.DialogInputValidationError{
+			Name:  f,
+			Error: err,
+		})
+	}
+
+	_ = json.NewEncoder(w).Encode(slack.DialogInputValidationErrors{
+		Errors: errs,
+	})
+}
+
+// publishJobPost sends the job post to the jobs channel, stores it and records the metrics.
+// Returns an error if the post could not be sent, in which case nothing else is done.
+func publishJobPost(botContext Context, post jobs.Post) error {
+	msg := formatJobPostMessage(post, botContext.JobPolicy.IsAgency(post))
+	ts, err := slackx.SendWithTimestamp(botContext.Client, "", post.Channel, msg, false, slack.MsgOptionDisableLinkUnfurl())
+	if err != nil {
+		return err
+	}
+
+	post.Timestamp = ts
+	post.Permalink = slackx.LinkToMessage(post.Channel, ts)
+	if botContext.JobPosts != nil {
+		// The post is already published, so it's not worth failing for this.
+		if err := botContext.JobPosts.Add(post); err != nil {
+			log.Printf("[ERROR] Failed to store job post %s: %s", ts, err)
+		}
+	}
+
+	// Notifying in the background, as Slack expects submissions to be answered within 3 seconds.
+	go notifyJobSubscribers(botContext, post)
+
+	// Sending metrics
+	attributes := map[string]interface{}{
+		"role":      cases.Title(language.English).String(strings.ToLower(post.Role)),
+		"company":   cases.Title(language.English).String(strings.ToLower(post.Company)),
+		"minSalary": post.MinSalary,
+		"maxSalary": post.MaxSalary,
+		"currency":  post.Currency,
+		"location":  post.Location,
+		"publisher": post.Publisher,
+		"job_link":  post.Link,
+		"user":      post.AuthorName,
+	}
+	if post.MaxSalaryEUR > 0 {
+		attributes["minSalaryEUR"] = post.MinSalaryEUR
+		attributes["maxSalaryEUR"] = post.MaxSalaryEUR
+	}
+	if post.Client != "" {
+		attributes["client"] = cases.Title(language.English).String(strings.ToLower(post.Client))
+	}
+	botContext.Harvester.RecordMetric(telemetry.Count{
+		Name:       fmt.Sprintf("%s.%s", strings.ToLower(botContext.Config.Bot.Name), "job_post.published"),
+		Attributes: attributes,
+		Value:      1,
+		Timestamp:  time.Now(),
+	})
+
+	return nil
+}
+
+// notifyJobSubscribers sends a DM to every user with a job alert matching the published post.
+func notifyJobSubscribers(botContext Context, post jobs.Post) {
+	if botContext.JobSubscriptions == nil {
+		return
+	}
+
+	for _, sub := range botContext.JobSubscriptions.Matching(post, botContext.ExchangeRates) {
+		msg := fmt.Sprintf(":bell: New job post matching your alert (%s): <%s|%s @ %s>\nUse `jobs unsubscribe %s` to stop receiving this alert.", sub, post.Permalink, post.Role, post.Company, sub.ID)
+		if err := slackx.Send(botContext.Client, "", sub.User, msg, false); err != nil {
+			log.Printf("[ERROR] Failed to notify job alert %s: %s", sub.ID, err)
+		}
+	}
+}
+
+// formatJobPostMessage formats the job post as published in the jobs channel.
+// Posts published by agencies get a distinct badge and show the final client as company.
+func formatJobPostMessage(post jobs.Post, agency bool) string {
+	minSalaryStr := fmt.Sprintf("%dK", post.MinSalary)
+	if post.MinSalary == -1 {
+		minSalaryStr = ""
+	}
+
+	salaryEURStr := ""
+	if post.MaxSalaryEUR > 0 && post.Currency != jobs.BaseCurrency {
+		salaryEURStr = fmt.Sprintf(" (≈ %s EUR)", formatSalaryRange(jobs.Salary{Min: post.MinSalaryEUR, Max: post.MaxSalaryEUR}))
+	}
+
+	badge := ""
+	company := post.Company
+	if agency {
+		badge = ":office: *[Agency]* "
+		company = fmt.Sprintf("%s (via %s)", post.Client, post.Company)
+	}
+
+	return fmt.Sprintf("%s:computer: %s @ %s - :moneybag: %s - %dK %s%s - :round_pushpin: %s - :lower_left_fountain_pen: %s - :link: <%s|Link> - :raised_hands: More info DM <@%s>",
+		badge,
+		post.Role,
+		company,
+		minSalaryStr,
+		post.MaxSalary,
+		post.Currency,
+		salaryEURStr,
+		post.Location,
+		post.Publisher,
+		post.Link,
+		post.AuthorName,
+	)
+}
+
+// requestJobPostReview queues the job post and sends a preview to the staff channel, so any staff member can decide on it.
+// The submission is dropped if the preview can't be sent or recorded, as nobody could decide on it otherwise.
+func requestJobPostReview(botContext Context, post jobs.Post) error {
+	submission, err := botContext.JobQueue.Enqueue(post, time.Now())
+	if err != nil {
+		return err
+	}
+
+	channel, ts, err := botContext.Client.PostMessage(botContext.Config.Channels.Staff,
+		slack.MsgOptionText("New job post waiting for review", false),
+		slack.MsgOptionBlocks(jobPostReviewBlocks(submission, botContext.JobPolicy.IsAgency(post))...),
+		slack.MsgOptionDisableLinkUnfurl(),
+	)
+	if err == nil {
+		if err = botContext.JobQueue.SetReviewMessage(submission.ID, channel, ts); err != nil {
+			// The preview buttons would point to a submission without review message.
+			_, _, _ = botContext.Client.DeleteMessage(channel, ts)
+		}
+	}
+	if err != nil {
+		if removeErr := botContext.JobQueue.Remove(submission.ID); removeErr != nil {
+			log.Printf("[ERROR] Failed to drop job post submission %s: %s", submission.ID, removeErr)
+		}
+		return err
+	}
+
+	_ = slackx.Send(botContext.Client, "", post.Author, "Thanks! Your job post has been sent to the Staff for review. You will be notified once it is reviewed.", false)
+
+	return nil
+}
+
+func jobPostReviewBlocks(submission jobs.Submission, agency bool) []slack.Block {
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("<@%s> submitted a job post that needs review:", submission.Post.Author), false, false), nil, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, formatJobPostMessage(submission.Post, agency), false, false), nil, nil),
+	}
+
+	if d := submission.Decision; d != nil {
+		text := fmt.Sprintf("*%s* by <@%s> on %s", jobPostDecisionLabels[d.Status], d.By, d.At.Format("2006-01-02 15:04"))
+		if d.Reason != "" {
+			text += fmt.Sprintf(": %s", d.Reason)
+		}
+
+		return append(blocks, slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, text, false, false)))
+	}
+
+	approve := slack.NewButtonBlockElement(actionJobPostApprove, submission.ID, slack.NewTextBlockObject(slack.PlainTextType, "Approve", false, false))
+	approve.Style = slack.StylePrimary
+	reject := slack.NewButtonBlockElement(actionJobPostReject, submission.ID, slack.NewTextBlockObject(slack.PlainTextType, "Reject", false, false))
+	reject.Style = slack.StyleDanger
+	requestChanges := slack.NewButtonBlockElement(actionJobPostRequestChanges, submission.ID, slack.NewTextBlockObject(slack.PlainTextType, "Request changes", false, false))
+
+	return append(blocks, slack.NewActionBlock("job_post_review", approve, reject, requestChanges))
+}
+
+var jobPostDecisionLabels = map[string]string{
+	jobs.StatusApproved:         "Approved",
+	jobs.StatusRejected:         "Rejected",
+	jobs.StatusChangesRequested: "Changes requested",
+}
+
+// handleJobPostReviewAction handles the buttons of the job post review message.
+// Approvals are applied right away, while rejections and change requests open a modal asking for the reason.
+func handleJobPostReviewAction(botContext Context, message slack.InteractionCallback, action *slack.BlockAction) {
+	if !botContext.IsStaff(message.User.ID) {
+		if resp, err := botContext.Client.OpenView(message.TriggerID, userNotAllowedModal()); err != nil {
+			logModalError(err, resp)
+		}
+		return
+	}
+
+	switch action.ActionID {
+	case actionJobPostApprove:
+		// Deciding in the background, as Slack expects interactions to be answered within 3 seconds.
+		go decideJobPost(botContext, action.Value, jobs.Decision{Status: jobs.StatusApproved, By: message.User.ID, At: time.Now()})
+	case actionJobPostReject, actionJobPostRequestChanges:
+		modal := generateJobPostReviewReasonModal(action.ActionID)
+		modal.PrivateMetadata = action.Value // persist the submission ID across submission
+		if resp, err := botContext.Client.OpenView(message.TriggerID, modal); err != nil {
+			logModalError(err, resp)
+		}
+	}
+}
+
+// handleJobPostReviewReasonSubmission handles the submission of the rejection and change request modals.
+func handleJobPostReviewReasonSubmission(botContext Context, w http.ResponseWriter, message slack.InteractionCallback) {
+	// We early set the Content-Type header for any response. This is important.
+	w.Header().Set("Content-Type", "application/json")
+
+	if !botContext.IsStaff(message.User.ID) {
+		_ = json.NewEncoder(w).Encode(
+			slack.NewErrorsViewSubmissionResponse(map[string]string{"reason": "You are not allowed to review job posts."}),
+		)
+		return
+	}
+
+	status := jobs.StatusRejected
+	if message.View.CallbackID == actionJobPostRequestChanges {
+		status = jobs.StatusChangesRequested
+	}
+
+	reason := strings.TrimSpace(message.View.State.Values["reason"]["reason"].Value)
+	submissionID := strings.Trim(message.View.PrivateMetadata, `"`) // For some reason, slack adds an extra double quote
+
+	_ = json.NewEncoder(w).Encode(slack.NewClearViewSubmissionResponse())
+
+	go decideJobPost(botContext, submissionID, jobs.Decision{Status: status, By: message.User.ID, Reason: reason, At: time.Now()})
+}
+
+// decideJobPost records the staff decision on a job post submission, publishes it if approved,
+// notifies the author and updates the review message. Approved posts that can't be published are left pending.
+func decideJobPost(botContext Context, submissionID string, decision jobs.Decision) {
+	submission, err := botContext.JobQueue.Decide(submissionID, decision)
+	if err != nil {
+		if errors.Is(err, jobs.ErrSubmissionDecided) {
+			_ = slackx.SendEphemeral(botContext.Client, "", submission.ReviewChannel, decision.By, fmt.Sprintf("This job post was already reviewed by <@%s>.", submission.Decision.By))
+			return
+		}
+		log.Printf("[ERROR] Failed to review job post submission %s: %s", submissionID, err)
+		if pending, err := botContext.JobQueue.Get(submissionID); err == nil {
+			_ = slackx.SendEphemeral(botContext.Client, "", pending.ReviewChannel, decision.By, "Sorry, your review could not be saved, so the job post is still pending. Please try again.")
+		}
+		return
+	}
+
+	if decision.Status == jobs.StatusApproved {
+		post := submission.Post
+		post.PublishedAt = time.Now()
+		if err := publishJobPost(botContext, post); err != nil {
+			log.Printf("[ERROR] Failed to publish approved job post submission %s: %s", submission.ID, err)
+			msg := "Sorry, the job post could not be published, so it is still pending. Please try again."
+			if err := botContext.JobQueue.Reopen(submission.ID); err != nil {
+				log.Printf("[ERROR] Failed to reopen job post submission %s: %s", submission.ID, err)
+				msg = "Sorry, the job post could not be published nor set back to pending. Please ask the author to submit it again."
+			}
+			_ = slackx.SendEphemeral(botContext.Client, "", submission.ReviewChannel, decision.By, msg)
+			return
+		}
+	}
+
+	log.Printf("Job post submission %s %s by %s", submission.ID, submission.Status, decision.By)
+
+	if msg := jobPostDecisionMessage(decision); msg != "" {
+		_ = slackx.Send(botContext.Client, "", submission.Post.Author, msg, false)
+	}
+
+	agency := botContext.JobPolicy.IsAgency(submission.Post)
+	if _, _, _, err := botContext.Client.UpdateMessage(submission.ReviewChannel, submission.ReviewTimestamp, slack.MsgOptionText("Job post reviewed", false), slack.MsgOptionBlocks(jobPostReviewBlocks(submission, agency)...)); err != nil {
+		log.Printf("[WARN] Failed to update review message of job post submission %s: %s", submission.ID, err)
+	}
+}
+
+// jobPostDecisionMessage returns the message sent to the author of the job post once reviewed.
+func jobPostDecisionMessage(decision jobs.Decision) string {
+	switch decision.Status {
+	case jobs.StatusApproved:
+		return "Your job post has been approved and published. Thanks!"
+	case jobs.StatusRejected:
+		return fmt.Sprintf("Your job post has been rejected by the Staff.\n*Reason*: %s\nFeel free to reach any Staff member for more details.", decision.Reason)
+	case jobs.StatusChangesRequested:
+		return fmt.Sprintf("The Staff reviewed your job post and asks for some changes before publishing it:\n>%s\nPlease submit it again once updated.", decision.Reason)
+	}
+
+	return ""
+}
+
+func generateJobPostReviewReasonModal(callbackID string) slack.ModalViewRequest {
+	title, label, placeholder := "Reject job post", "Reason", "It does not comply with the job board rules because..."
+	if callbackID == actionJobPostRequestChanges {
+		title, label, placeholder = "Request changes", "Changes requested", "Please include the salary range of..."
+	}
+
+	reasonInput := slack.NewPlainTextInputBlockElement(slack.NewTextBlockObject(slack.PlainTextType, placeholder, false, false), "reason")
+	reasonInput.Multiline = true
+	block := slack.NewInputBlock("reason", slack.NewTextBlockObject(slack.PlainTextType, label, false, false), slack.NewTextBlockObject(slack.PlainTextType, "It will be sent to the author of the job post.", false, false), reasonInput)
+
+	return slack.ModalViewRequest{
+		Type:  slack.VTModal,
+		Title: slack.NewTextBlockObject(slack.PlainTextType, title, false, false),
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			block,
+		}},
+		Submit:     slack.NewTextBlockObject(slack.PlainTextType, "Send", false, false),
+		CallbackID: callbackID,
+	}
+}
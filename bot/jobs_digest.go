@@ -0,0 +1,90 @@
+package bot
+
+import (
+	"context"
+	"log"
+	"path/filepath"
+	"time"
+
+	"github.com/bcneng/candebot/internal/jobs"
+	"github.com/bcneng/candebot/internal/storage"
+	"github.com/bcneng/candebot/slackx"
+)
+
+// jobsDigestState is persisted to avoid sending the same digest twice across restarts.
+type jobsDigestState struct {
+	LastSentAt time.Time `json:"last_sent_at"` // Last time the digest was sent, or failed to be.
+}
+
+// JobsDigest builds the digest of the job posts published the week before the one now belongs to.
+func JobsDigest(botContext Context, now time.Time) jobs.Digest {
+	from, to := jobs.PreviousWeek(now.In(jobsDigestLocation()))
+
+	var posts []jobs.Post
+	if botContext.JobPosts != nil {
+		posts = botContext.JobPosts.Posts()
+	}
+
+	return jobs.BuildDigest(posts, from, to, botContext.Config.Jobs.Digest.SalaryBands)
+}
+
+// JobsDigestChannel returns the ID of the channel the digest is posted to.
+func JobsDigestChannel(conf Config) string {
+	if conf.Jobs.Digest.Channel == JobsDigestChannelGeneral {
+		return conf.Channels.General
+	}
+
+	return conf.Channels.Jobs
+}
+
+// SendJobsDigest posts the digest of the previous week to the configured channel.
+func SendJobsDigest(botContext Context, now time.Time) error {
+	digest := JobsDigest(botContext, now)
+	return slackx.Send(botContext.Client, "", JobsDigestChannel(botContext.Config), digest.Format(), false)
+}
+
+// scheduleJobsDigest sends the digest every Monday at the configured hour until ctx is done.
+// If the bot was down at the scheduled time, the digest is sent once it is back, as long as it is still the same day.
+func scheduleJobsDigest(ctx context.Context, botContext Context) {
+	statePath := filepath.Join(botContext.Config.DataDir, "job_digest.json")
+	var state jobsDigestState
+	if err := storage.ReadJSON(statePath, &state); err != nil {
+		log.Printf("[ERROR] Reading the jobs digest state: %s", err)
+	}
+
+	hour := intValue(botContext.Config.Jobs.Digest.Hour)
+	for {
+		now := time.Now().In(jobsDigestLocation())
+		last := jobs.NextDigestTime(now.AddDate(0, 0, -7), hour)
+		next := last
+		if !state.LastSentAt.Before(last) || now.Sub(last) > 24*time.Hour {
+			next = jobs.NextDigestTime(now, hour)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+
+		// Failed attempts are recorded too, so they are not retried until the next week, not even after a restart,
+		// to avoid flooding the channel.
+		if err := SendJobsDigest(botContext, next); err != nil {
+			log.Printf("[ERROR] Sending the jobs digest: %s", err)
+		}
+
+		state.LastSentAt = time.Now()
+		if err := storage.WriteJSON(statePath, state); err != nil {
+			log.Printf("[ERROR] Saving the jobs digest state: %s", err)
+		}
+	}
+}
+
+func jobsDigestLocation() *time.Location {
+	loc, err := time.LoadLocation("Europe/Madrid")
+	if err != nil {
+		return time.UTC
+	}
+
+	return loc
+}
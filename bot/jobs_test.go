@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bcneng/candebot/internal/jobs"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatJobPostMessage(t *testing.T) {
+	post := jobs.Post{
+		AuthorName: "jane",
+		Role:       "Backend Engineer",
+		Company:    "Acme",
+		Location:   "Barcelona",
+		Publisher:  "Employer",
+		Currency:   "EUR",
+		MinSalary:  60,
+		MaxSalary:  90,
+		Link:       "https://acme.com/jobs/1",
+	}
+
+	t.Run("employer post", func(t *testing.T) {
+		require.Equal(t,
+			":computer: Backend Engineer @ Acme - :moneybag: 60K - 90K EUR - :round_pushpin: Barcelona - :lower_left_fountain_pen: Employer - :link: <https://acme.com/jobs/1|Link> - :raised_hands: More info DM <@jane>",
+			formatJobPostMessage(post, false),
+		)
+	})
+
+	t.Run("salary in other currencies shows the EUR equivalent", func(t *testing.T) {
+		usd := post
+		usd.Currency = "USD"
+		usd.MinSalaryEUR = 54
+		usd.MaxSalaryEUR = 81
+		require.Contains(t, formatJobPostMessage(usd, false), ":moneybag: 60K - 90K USD (≈ 54K - 81K EUR) -")
+	})
+
+	t.Run("agency post", func(t *testing.T) {
+		agency := post
+		agency.Company = "Good Agency"
+		agency.Client = "Acme"
+		agency.Publisher = "Agency"
+		msg := formatJobPostMessage(agency, true)
+		require.Contains(t, msg, ":office: *[Agency]* :computer: Backend Engineer @ Acme (via Good Agency)")
+	})
+}
+
+func TestJobPostReviewBlocks(t *testing.T) {
+	submission := jobs.Submission{ID: "abc", Post: jobs.Post{Author: "U123"}, Status: jobs.StatusPending}
+
+	blocks := jobPostReviewBlocks(submission, false)
+	actions, ok := blocks[len(blocks)-1].(*slack.ActionBlock)
+	require.True(t, ok, "pending submissions must offer the review actions")
+	require.Len(t, actions.Elements.ElementSet, 3)
+
+	submission.Status = jobs.StatusRejected
+	submission.Decision = &jobs.Decision{Status: jobs.StatusRejected, By: "USTAFF", Reason: "Spam", At: time.Now()}
+	blocks = jobPostReviewBlocks(submission, false)
+	decision, ok := blocks[len(blocks)-1].(*slack.ContextBlock)
+	require.True(t, ok, "decided submissions must show who decided")
+	text := decision.ContextElements.Elements[0].(*slack.TextBlockObject).Text
+	require.Contains(t, text, "*Rejected* by <@USTAFF>")
+	require.Contains(t, text, "Spam")
+}
+
+func TestJobPostDecisionMessage(t *testing.T) {
+	require.NotEmpty(t, jobPostDecisionMessage(jobs.Decision{Status: jobs.StatusApproved}))
+	require.Contains(t, jobPostDecisionMessage(jobs.Decision{Status: jobs.StatusRejected, Reason: "Spam"}), "Spam")
+	require.Contains(t, jobPostDecisionMessage(jobs.Decision{Status: jobs.StatusChangesRequested, Reason: "Add the salary"}), "Add the salary")
+	require.Empty(t, jobPostDecisionMessage(jobs.Decision{Status: jobs.StatusPending}))
+}
+
+func TestJobsDigest(t *testing.T) {
+	store, err := jobs.NewStore("")
+	require.NoError(t, err)
+
+	now := time.Date(2024, 5, 13, 9, 0, 0, 0, jobsDigestLocation()) // Monday
+	require.NoError(t, store.Add(jobs.Post{Role: "Backend", Company: "Acme", Location: "Barcelona", MaxSalaryEUR: 50, PublishedAt: now.AddDate(0, 0, -3)}))
+	require.NoError(t, store.Add(jobs.Post{Role: "Frontend", Company: "Acme", Location: "Barcelona", MaxSalaryEUR: 50, PublishedAt: now}))
+
+	var conf Config
+	conf.Channels = ConfigChannels{Jobs: "CJOBS", General: "CGENERAL"}
+	conf.Jobs.applyDefaults()
+
+	digest := JobsDigest(Context{Config: conf, JobPosts: store}, now)
+	require.Equal(t, 1, digest.Total, "only posts from the previous week must be included")
+	require.Equal(t, "CJOBS", JobsDigestChannel(conf))
+
+	conf.Jobs.Digest.Channel = JobsDigestChannelGeneral
+	require.Equal(t, "CGENERAL", JobsDigestChannel(conf))
+}
+
+// jobsSlackServer mocks the Slack API, failing to post messages in the given channel.
+func jobsSlackServer(t *testing.T, failingChannel string) (*slack.Client, func() []string) {
+	var mu sync.Mutex
+	var posted []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = r.ParseForm()
+		channel := r.PostForm.Get("channel")
+		mu.Lock()
+		posted = append(posted, r.URL.Path+" "+channel)
+		mu.Unlock()
+
+		if r.URL.Path == "/chat.postMessage" && channel == failingChannel {
+			_, _ = w.Write([]byte(`{"ok": false, "error": "channel_not_found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok": true, "channel": "` + channel + `", "ts": "1.1"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	return slack.New("test-token", slack.OptionAPIURL(server.URL+"/")), func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string{}, posted...)
+	}
+}
+
+func TestDecideJobPost_PublishFailure(t *testing.T) {
+	client, calls := jobsSlackServer(t, "CJOBS")
+	queue, err := jobs.NewQueue("")
+	require.NoError(t, err)
+	store, err := jobs.NewStore("")
+	require.NoError(t, err)
+
+	submission, err := queue.Enqueue(jobs.Post{Channel: "CJOBS", Author: "UAUTHOR", Role: "Backend Engineer", Company: "Acme"}, time.Now())
+	require.NoError(t, err)
+	require.NoError(t, queue.SetReviewMessage(submission.ID, "CSTAFF", "1.1"))
+
+	botContext := Context{Client: client, JobQueue: queue, JobPosts: store}
+	decideJobPost(botContext, submission.ID, jobs.Decision{Status: jobs.StatusApproved, By: "USTAFF", At: time.Now()})
+
+	pending, err := queue.Get(submission.ID)
+	require.NoError(t, err)
+	require.Equal(t, jobs.StatusPending, pending.Status, "the submission must be retryable")
+	require.Empty(t, store.Posts())
+	require.NotContains(t, calls(), "/chat.postMessage UAUTHOR", "the author must not be told the post was published")
+	require.NotContains(t, calls(), "/chat.update CSTAFF")
+	require.Contains(t, calls(), "/chat.postEphemeral CSTAFF", "the reviewer must be told")
+}
+
+func TestRequestJobPostReview_PostFailure(t *testing.T) {
+	client, _ := jobsSlackServer(t, "CSTAFF")
+	queue, err := jobs.NewQueue("")
+	require.NoError(t, err)
+
+	botContext := Context{Client: client, JobQueue: queue, Config: Config{Channels: ConfigChannels{Staff: "CSTAFF"}}}
+	post := jobs.Post{Author: "UAUTHOR", Role: "Backend Engineer", Company: "Acme"}
+	require.Error(t, requestJobPostReview(botContext, post))
+
+	_, found := queue.FindDuplicate(post)
+	require.False(t, found, "submissions that can't be reviewed must not be kept")
+}
@@ -0,0 +1,231 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/bcneng/candebot/internal/audit"
+	"github.com/bcneng/candebot/internal/moderation"
+	"github.com/bcneng/candebot/slackx"
+)
+
+// RecordModerationEvent adds the event to the moderation history of the user, alerting staff the moment the user
+// reaches the configured number of events within the alert window.
+func RecordModerationEvent(botContext Context, r moderation.Record) {
+	if botContext.ModerationHistory == nil || r.User == "" {
+		return
+	}
+	if r.At.IsZero() {
+		r.At = time.Now()
+	}
+
+	conf := botContext.Config.Moderation
+	since := r.At.AddDate(0, 0, -conf.AlertWindowDays)
+	count, err := botContext.ModerationHistory.Add(r, since)
+	if err != nil {
+		log.Printf("[ERROR] Failed to record %s moderation event of %s: %s", r.Kind, r.User, err)
+	}
+
+	// Alerting only when the threshold is crossed, not on every event after it.
+	if count != conf.AlertThreshold {
+		return
+	}
+
+	msg := fmt.Sprintf(":rotating_light: <@%s> has %d moderation events in the last %d days, latest one %s. Run `mod history <@%s>` for details.",
+		r.User, count, conf.AlertWindowDays, moderationKindLabel(r.Kind), r.User)
+	if err := slackx.Send(botContext.Client, "", botContext.Config.Channels.Staff, msg, false); err != nil {
+		log.Printf("[ERROR] Failed to alert staff about %s moderation history: %s", r.User, err)
+	}
+}
+
+var moderationKindLabels = map[string]string{
+	moderation.KindReport:    "a message report",
+	moderation.KindRateLimit: "a rate limit deletion",
+	moderation.KindInclusion: "an inclusive language warning",
+	moderation.KindTracking:  "a tracking link warning",
+	moderation.KindWarning:   "a formal warning",
+	moderation.KindSanction:  "a timeout or restriction",
+}
+
+func moderationKindLabel(kind string) string {
+	if label, ok := moderationKindLabels[kind]; ok {
+		return label
+	}
+
+	return kind
+}
+
+// FormatModerationHistory lists the given moderation records of a user, most recent first, counting the ones within the alert window.
+func FormatModerationHistory(user string, records []moderation.Record, windowDays int, now time.Time) string {
+	if len(records) == 0 {
+		return fmt.Sprintf("<@%s> has no moderation history.", user)
+	}
+
+	since := now.AddDate(0, 0, -windowDays)
+	var recent int
+	for _, r := range records {
+		if !r.At.Before(since) {
+			recent++
+		}
+	}
+
+	msg := fmt.Sprintf("<@%s> has %d moderation events, %d of them in the last %d days:\n", user, len(records), recent, windowDays)
+	for i := len(records) - 1; i >= 0; i-- {
+		r := records[i]
+		line := fmt.Sprintf("• %s: %s", r.At.Format("2006-01-02 15:04"), moderationKindLabel(r.Kind))
+		if r.Channel != "" {
+			line += fmt.Sprintf(" in <#%s>", r.Channel)
+		}
+		if r.Detail != "" {
+			line += fmt.Sprintf(" (%s)", r.Detail)
+		}
+		msg += line + "\n"
+	}
+
+	return msg
+}
+
+// sanctionsCheckInterval is how often expired sanctions are lifted.
+const sanctionsCheckInterval = time.Minute
+
+// WarnUser sends the user a formal warning about breaking the given Code of Conduct section, logging it to the staff channel.
+func WarnUser(botContext Context, by, user, section, note string) error {
+	s, ok := moderation.FindCoCSection(botContext.Config.Moderation.CoCSections, section)
+	if !ok {
+		return fmt.Errorf("unknown Code of Conduct section %q. Available sections: %s", section, cocSectionKeys(botContext.Config.Moderation.CoCSections))
+	}
+
+	err := slackx.Send(botContext.Client, "", user, moderation.FormatWarning(s, botContext.Config.Links.COC, note), false)
+	Audit(botContext, audit.Entry{Actor: by, Action: "mod_warn", Target: user, Params: map[string]string{"section": s.Key, "note": note}}, err)
+	if err != nil {
+		return fmt.Errorf("warning the user: %w", err)
+	}
+
+	RecordModerationEvent(botContext, moderation.Record{User: user, Kind: moderation.KindWarning, Detail: s.Title})
+	logModerationAction(botContext, fmt.Sprintf(":warning: <@%s> warned <@%s> about *%s*.%s", by, user, s.Title, moderationNote(note)))
+
+	return nil
+}
+
+// ImposeSanction times out or restricts the user, replacing any previous sanction. The user is notified by DM
+// and the action is logged to the staff channel.
+func ImposeSanction(botContext Context, s moderation.Sanction) error {
+	err := botContext.Sanctions.Impose(s)
+	params := map[string]string{"reason": s.Reason}
+	if !s.Until.IsZero() {
+		params["until"] = s.Until.UTC().Format(time.RFC3339)
+	}
+	Audit(botContext, audit.Entry{Actor: s.By, Action: "mod_" + s.Type, Target: s.User, Params: params}, err)
+	if err != nil {
+		return err
+	}
+
+	var msg, action string
+	switch s.Type {
+	case moderation.SanctionTimeout:
+		action = fmt.Sprintf("timed out <@%s> until %s", s.User, slackDate(s.Until))
+		msg = fmt.Sprintf("You have been timed out by the staff until %s. Meanwhile, your new messages will be deleted, except thread replies.", slackDate(s.Until))
+	case moderation.SanctionRestriction:
+		action = fmt.Sprintf("restricted <@%s> until further notice", s.User)
+		msg = "You have been restricted by the staff. Meanwhile, all your new messages will be deleted. Please, reach out to any staff member to talk about it."
+	}
+	if s.Reason != "" {
+		msg += fmt.Sprintf("\nReason: %s", s.Reason)
+	}
+	_ = slackx.Send(botContext.Client, "", s.User, msg, false)
+
+	RecordModerationEvent(botContext, moderation.Record{User: s.User, Kind: moderation.KindSanction, Detail: s.Type, At: s.Since})
+	logModerationAction(botContext, fmt.Sprintf(":no_entry: <@%s> %s.%s", s.By, action, moderationNote(s.Reason)))
+
+	return nil
+}
+
+// LiftSanction lifts the active sanction of the user. The user is notified by DM and the action is logged to the staff channel.
+func LiftSanction(botContext Context, by, user string) error {
+	s, err := botContext.Sanctions.Lift(user)
+	Audit(botContext, audit.Entry{Actor: by, Action: "mod_lift", Target: user}, err)
+	if err != nil {
+		return err
+	}
+
+	notifySanctionLifted(botContext, s, fmt.Sprintf("<@%s>", by))
+
+	return nil
+}
+
+// ActiveSanction returns the sanction that applies to a new message of the user in the given channel, if any.
+func ActiveSanction(botContext Context, user, channel string, threadReply bool) (moderation.Sanction, bool) {
+	if botContext.Sanctions == nil {
+		return moderation.Sanction{}, false
+	}
+	if _, ok := botContext.SanctionChannels[channel]; botContext.SanctionChannels != nil && !ok {
+		return moderation.Sanction{}, false
+	}
+
+	s, ok := botContext.Sanctions.Active(user, time.Now())
+	if !ok || !s.Applies(threadReply) {
+		return moderation.Sanction{}, false
+	}
+
+	return s, true
+}
+
+// liftExpiredSanctions periodically lifts the expired sanctions until the context is done.
+func liftExpiredSanctions(ctx context.Context, botContext Context) {
+	ticker := time.NewTicker(sanctionsCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			expired, err := botContext.Sanctions.Expire(now)
+			if err != nil {
+				log.Printf("[ERROR] Lifting expired sanctions: %s", err)
+			}
+			for _, s := range expired {
+				Audit(botContext, audit.Entry{Actor: audit.ActorBot, Action: "mod_lift", Target: s.User, Params: map[string]string{"reason": s.Type + " expired"}}, nil)
+				notifySanctionLifted(botContext, s, "the bot")
+			}
+		}
+	}
+}
+
+func notifySanctionLifted(botContext Context, s moderation.Sanction, by string) {
+	_ = slackx.Send(botContext.Client, "", s.User, fmt.Sprintf("Your %s is over, you can post messages again. Please, keep our Code of Conduct in mind: %s", s.Type, botContext.Config.Links.COC), false)
+	logModerationAction(botContext, fmt.Sprintf(":white_check_mark: The %s of <@%s> was lifted by %s.", s.Type, s.User, by))
+}
+
+// logModerationAction posts the moderation action to the staff channel.
+func logModerationAction(botContext Context, msg string) {
+	log.Printf("[INFO] Moderation: %s", msg)
+	if err := slackx.Send(botContext.Client, "", botContext.Config.Channels.Staff, msg, false); err != nil {
+		log.Printf("[ERROR] Failed to log moderation action to the staff channel: %s", err)
+	}
+}
+
+func moderationNote(note string) string {
+	if note == "" {
+		return ""
+	}
+
+	return fmt.Sprintf(" Notes: %s", note)
+}
+
+func cocSectionKeys(sections []moderation.CoCSection) string {
+	keys := make([]string, 0, len(sections))
+	for _, s := range sections {
+		keys = append(keys, s.Key)
+	}
+
+	return strings.Join(keys, ", ")
+}
+
+// slackDate formats the time to be displayed in the timezone of the reader.
+func slackDate(t time.Time) string {
+	return fmt.Sprintf("<!date^%d^{date_short_pretty} at {time}|%s>", t.Unix(), t.UTC().Format("2006-01-02 15:04 MST"))
+}
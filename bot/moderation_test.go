@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bcneng/candebot/internal/moderation"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRecordModerationEvent(t *testing.T) {
+	var alerts []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = r.ParseForm()
+		alerts = append(alerts, r.PostForm.Get("text"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"ok": true, "channel": "CSTAFF", "ts": "1.1"}`))
+	}))
+	defer server.Close()
+
+	history, err := moderation.NewHistory("")
+	require.NoError(t, err)
+
+	botContext := Context{
+		Client:            slack.New("test-token", slack.OptionAPIURL(server.URL+"/")),
+		ModerationHistory: history,
+		Config: Config{
+			Channels:   ConfigChannels{Staff: "CSTAFF"},
+			Moderation: ConfigModeration{AlertThreshold: 2, AlertWindowDays: 7},
+		},
+	}
+
+	now := time.Now()
+	RecordModerationEvent(botContext, moderation.Record{User: "U1", Kind: moderation.KindReport, At: now.AddDate(0, 0, -8)})
+	RecordModerationEvent(botContext, moderation.Record{User: "U1", Kind: moderation.KindTracking, At: now})
+	require.Empty(t, alerts, "events out of the window must not count")
+
+	RecordModerationEvent(botContext, moderation.Record{User: "U2", Kind: moderation.KindTracking, At: now})
+	RecordModerationEvent(botContext, moderation.Record{User: "U1", Kind: moderation.KindInclusion, At: now})
+	require.Len(t, alerts, 1)
+	require.Contains(t, alerts[0], "<@U1> has 2 moderation events in the last 7 days, latest one an inclusive language warning")
+
+	RecordModerationEvent(botContext, moderation.Record{User: "U1", Kind: moderation.KindRateLimit, At: now})
+	require.Len(t, alerts, 1, "staff is alerted only once per threshold crossing")
+
+	msg := FormatModerationHistory("U1", history.ForUser("U1"), 7, now)
+	require.Contains(t, msg, "<@U1> has 4 moderation events, 3 of them in the last 7 days:\n")
+	require.Contains(t, msg, "a message report")
+	require.Equal(t, "<@U3> has no moderation history.", FormatModerationHistory("U3", nil, 7, now))
+}
+
+func TestActiveSanction(t *testing.T) {
+	sanctions, err := moderation.NewSanctions("")
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, sanctions.Impose(moderation.Sanction{User: "UTIMEOUT", Type: moderation.SanctionTimeout, Since: now, Until: now.Add(time.Hour)}))
+	require.NoError(t, sanctions.Impose(moderation.Sanction{User: "URESTRICTED", Type: moderation.SanctionRestriction, Since: now}))
+
+	botContext := Context{Sanctions: sanctions, SanctionChannels: map[string]struct{}{"CGENERAL": {}}}
+
+	tests := []struct {
+		name        string
+		user        string
+		channel     string
+		threadReply bool
+		want        bool
+	}{
+		{name: "top level message under timeout", user: "UTIMEOUT", channel: "CGENERAL", want: true},
+		{name: "thread reply under timeout", user: "UTIMEOUT", channel: "CGENERAL", threadReply: true},
+		{name: "thread reply under restriction", user: "URESTRICTED", channel: "CGENERAL", threadReply: true, want: true},
+		{name: "channel out of scope", user: "URESTRICTED", channel: "CRANDOM"},
+		{name: "member without sanction", user: "UOTHER", channel: "CGENERAL"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := ActiveSanction(botContext, tt.user, tt.channel, tt.threadReply)
+			require.Equal(t, tt.want, ok)
+		})
+	}
+
+	botContext.SanctionChannels = nil
+	_, ok := ActiveSanction(botContext, "URESTRICTED", "CRANDOM", false)
+	require.True(t, ok, "sanctions apply everywhere if no channels are configured")
+}
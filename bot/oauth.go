@@ -0,0 +1,104 @@
+package bot
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/bcneng/candebot/internal/oauth"
+	"github.com/slack-go/slack"
+)
+
+// oauthStateTTL is how long members have to complete the OAuth flow since they got the link.
+const oauthStateTTL = 30 * time.Minute
+
+// oauthUserScopes are the scopes of the user tokens the bot asks for: just editing the messages of the member.
+var oauthUserScopes = []string{"chat:write"}
+
+// Overridden in tests, as slack-go always exchanges OAuth codes against slack.com, and to use a fake Slack API.
+var (
+	exchangeOAuthCode = func(clientID, clientSecret, code, redirectURL string) (*slack.OAuthV2Response, error) {
+		return slack.GetOAuthV2Response(http.DefaultClient, clientID, clientSecret, code, redirectURL)
+	}
+	newUserClient = func(token string) *slack.Client { return slack.New(token) }
+)
+
+// AuthorizeLink returns the link for the user to let the bot edit their messages. Empty if the OAuth flow is disabled.
+func AuthorizeLink(botContext Context, user string) string {
+	if botContext.UserTokens == nil {
+		return ""
+	}
+
+	conf := botContext.Config.OAuth
+	state := oauth.SignState([]byte(botContext.Config.Bot.Server.SigningSecret), user, time.Now().Add(oauthStateTTL))
+	return oauth.AuthorizeLink(conf.ClientID, conf.RedirectURL, state, oauthUserScopes...)
+}
+
+// UserClient returns a Slack client acting on behalf of the user, or oauth.ErrNoToken if the user didn't authorize the bot.
+func UserClient(botContext Context, user string) (*slack.Client, error) {
+	if botContext.UserTokens == nil {
+		return nil, oauth.ErrNoToken
+	}
+
+	token, err := botContext.UserTokens.Get(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return newUserClient(token), nil
+}
+
+// forgetRevokedToken deletes the token of the user if the error says it's no longer valid.
+func forgetRevokedToken(botContext Context, user string, err error) {
+	var slackErr slack.SlackErrorResponse
+	if !errors.As(err, &slackErr) {
+		return
+	}
+
+	switch slackErr.Err {
+	case "invalid_auth", "not_authed", "token_revoked", "token_expired", "account_inactive":
+		log.Printf("[INFO] Forgetting the token of %s: %s", user, slackErr.Err)
+		if err := botContext.UserTokens.Delete(user); err != nil {
+			log.Printf("[ERROR] Deleting the token of %s: %s", user, err)
+		}
+	}
+}
+
+// oauthCallbackHandler completes the OAuth flow, storing the token granted by the member.
+func oauthCallbackHandler(botContext Context) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		user, err := oauth.VerifyState([]byte(botContext.Config.Bot.Server.SigningSecret), query.Get("state"), time.Now())
+		if err != nil {
+			http.Error(w, "This authorization link is invalid or expired. Please get a new one from Slack.", http.StatusBadRequest)
+			return
+		}
+
+		if query.Get("error") != "" {
+			_, _ = w.Write([]byte("Authorization cancelled. You can close this window and go back to Slack."))
+			return
+		}
+
+		conf := botContext.Config.OAuth
+		resp, err := exchangeOAuthCode(conf.ClientID, conf.ClientSecret, query.Get("code"), conf.RedirectURL)
+		if err != nil {
+			log.Printf("[ERROR] Exchanging OAuth code of %s: %s", user, err)
+			http.Error(w, "Slack could not complete the authorization. Please try again later.", http.StatusBadGateway)
+			return
+		}
+		if resp.AuthedUser.ID != user || resp.AuthedUser.AccessToken == "" {
+			http.Error(w, "This authorization link was issued for another member.", http.StatusBadRequest)
+			return
+		}
+
+		if err := botContext.UserTokens.Set(user, resp.AuthedUser.AccessToken); err != nil {
+			log.Printf("[ERROR] Storing the token of %s: %s", user, err)
+			http.Error(w, "The authorization could not be saved. Please try again later.", http.StatusInternalServerError)
+			return
+		}
+
+		log.Printf("[INFO] %s authorized the bot to edit their messages", user)
+		_, _ = w.Write([]byte("All set! You can close this window and go back to Slack."))
+	}
+}
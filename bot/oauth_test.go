@@ -0,0 +1,60 @@
+package bot
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/bcneng/candebot/internal/crypto"
+	"github.com/bcneng/candebot/internal/oauth"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOAuthCallback(t *testing.T) {
+	defer func(original func(string, string, string, string) (*slack.OAuthV2Response, error)) {
+		exchangeOAuthCode = original
+	}(exchangeOAuthCode)
+	exchangeOAuthCode = func(clientID, clientSecret, code, redirectURL string) (*slack.OAuthV2Response, error) {
+		resp := &slack.OAuthV2Response{}
+		resp.AuthedUser.ID = "U1"
+		resp.AuthedUser.AccessToken = "xoxp-" + code
+		return resp, nil
+	}
+
+	cipher, err := crypto.NewCipher("MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=")
+	require.NoError(t, err)
+	tokens, err := oauth.NewTokens("", cipher)
+	require.NoError(t, err)
+	botContext := Context{
+		Config:     Config{Bot: ConfigBot{Server: ConfigBotServer{SigningSecret: "secret"}}},
+		UserTokens: tokens,
+	}
+	handler := oauthCallbackHandler(botContext)
+
+	callback := func(user, code string) *httptest.ResponseRecorder {
+		query := url.Values{}
+		query.Set("code", code)
+		query.Set("state", oauth.SignState([]byte("secret"), user, time.Now().Add(time.Minute)))
+		rec := httptest.NewRecorder()
+		handler(rec, httptest.NewRequest(http.MethodGet, "/oauth/callback?"+query.Encode(), nil))
+		return rec
+	}
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/oauth/callback?code=abc&state=U1.123.forged", nil))
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = callback("U2", "abc")
+	require.Equal(t, http.StatusBadRequest, rec.Code, "the token was granted by another member")
+	_, err = tokens.Get("U2")
+	require.ErrorIs(t, err, oauth.ErrNoToken)
+
+	rec = callback("U1", "abc")
+	require.Equal(t, http.StatusOK, rec.Code)
+	token, err := tokens.Get("U1")
+	require.NoError(t, err)
+	require.Equal(t, "xoxp-abc", token)
+}
@@ -0,0 +1,476 @@
+package bot
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bcneng/candebot/internal/audit"
+	"github.com/bcneng/candebot/internal/moderation"
+	"github.com/bcneng/candebot/internal/reports"
+	"github.com/bcneng/candebot/slackx"
+	"github.com/newrelic/newrelic-telemetry-sdk-go/telemetry"
+	"github.com/slack-go/slack"
+)
+
+// Block Kit action IDs of the report notification sent to staff.
+// Resolve and warn user IDs are also used as callback IDs of the modals they open.
+const (
+	actionReportTake          = "report_take"
+	actionReportResolve       = "report_resolve"
+	actionReportDeleteMessage = "report_delete_message"
+	actionReportWarnUser      = "report_warn_user"
+)
+
+var reportStatusLabels = map[string]string{
+	reports.StatusOpen:         "Open",
+	reports.StatusAcknowledged: "Acknowledged",
+	reports.StatusResolved:     "Resolved",
+}
+
+// reportSnapshotContextSize is the number of messages around the reported one included in the snapshot.
+const reportSnapshotContextSize = 4
+
+// reportMessageMetadata persists the reported message channel, timestamp, thread timestamp and author across the report modal submission.
+func reportMessageMetadata(channel, ts, threadTS, author string) string {
+	return fmt.Sprintf("%s|%s|%s|%s", channel, ts, threadTS, author)
+}
+
+// handleReportSubmission opens a case for the reported message and notifies staff about it.
+// The identity of anonymous reporters is encrypted, so staff can't see it.
+func handleReportSubmission(botContext Context, message slack.InteractionCallback) {
+	metadata := strings.Split(strings.Trim(sanitizeReportState(message.View.PrivateMetadata), `"`), "|") // For some reason, slack adds an extra double quote
+	if len(metadata) != 4 {
+		log.Printf("[ERROR] Invalid report modal metadata %q", message.View.PrivateMetadata)
+		return
+	}
+
+	values := message.View.State.Values
+	c := reports.Case{
+		Reporter:         message.User.ID,
+		ReporterName:     message.User.Name,
+		Reason:           strings.TrimSpace(values["reason"]["reason"].Value),
+		Scale:            values["scale"]["scale"].SelectedOption.Value,
+		Channel:          metadata[0],
+		MessageTimestamp: metadata[1],
+		MessageAuthor:    metadata[3],
+		Snapshot:         captureReportSnapshot(botContext, metadata[0], metadata[1], metadata[2]),
+		CreatedAt:        time.Now(),
+	}
+
+	if len(values["anonymous"]["anonymous"].SelectedOptions) > 0 && botContext.ReportCipher != nil {
+		encrypted, err := botContext.ReportCipher.Encrypt(message.User.ID)
+		if err != nil {
+			log.Printf("[ERROR] Failed to encrypt anonymous reporter: %s", err)
+			_ = slackx.Send(botContext.Client, "", message.User.ID, "Sorry, your anonymous report could not be sent. Please try again later.", false)
+			return
+		}
+
+		c.Anonymous = true
+		c.EncryptedReporter = encrypted
+		c.Reporter = ""
+		c.ReporterName = ""
+	}
+
+	c, err := botContext.Reports.Open(c)
+	if err != nil {
+		log.Printf("[ERROR] Failed to open report case: %s", err)
+		return
+	}
+
+	channel, ts, err := botContext.Client.PostMessage(botContext.Config.Channels.Staff,
+		slack.MsgOptionText(fmt.Sprintf("New message report #%d", c.ID), false),
+		slack.MsgOptionBlocks(reportCaseBlocks(c)...),
+		slack.MsgOptionDisableLinkUnfurl(),
+	)
+	if err != nil {
+		log.Printf("[ERROR] Failed to notify report case #%d: %s", c.ID, err)
+	} else if err := botContext.Reports.SetNotification(c.ID, channel, ts); err != nil {
+		log.Printf("[WARN] Failed to record notification of report case #%d: %s", c.ID, err)
+	}
+
+	RecordModerationEvent(botContext, moderation.Record{
+		User:    c.MessageAuthor,
+		Kind:    moderation.KindReport,
+		Channel: c.Channel,
+		Detail:  fmt.Sprintf("case #%d", c.ID),
+		At:      c.CreatedAt,
+	})
+
+	// Sending metrics
+	botContext.Harvester.RecordMetric(telemetry.Count{
+		Name: fmt.Sprintf("%s.%s", strings.ToLower(botContext.Config.Bot.Name), "report_message.received"),
+		Attributes: map[string]interface{}{
+			"scale": c.Scale,
+		},
+		Value:     1,
+		Timestamp: time.Now(),
+	})
+}
+
+// captureReportSnapshot fetches the reported message and the messages around it. Returns nil if the message can't be fetched.
+func captureReportSnapshot(botContext Context, channel, ts, threadTS string) *reports.Snapshot {
+	msg, surrounding, err := slackx.FetchMessage(botContext.Client, channel, ts, threadTS, reportSnapshotContextSize)
+	if err != nil {
+		log.Printf("[WARN] Failed to capture the reported message %s in %s: %s", ts, channel, err)
+		return nil
+	}
+
+	snapshot := &reports.Snapshot{Message: snapshotMessage(msg), CapturedAt: time.Now()}
+	for _, m := range surrounding {
+		snapshot.Context = append(snapshot.Context, snapshotMessage(m))
+	}
+
+	return snapshot
+}
+
+func snapshotMessage(msg slack.Message) reports.SnapshotMessage {
+	m := reports.SnapshotMessage{Author: msg.User, Text: msg.Text, Timestamp: msg.Timestamp}
+	if m.Author == "" {
+		m.Author = msg.BotID
+	}
+	m.PostedAt, _ = slackx.TimestampToTime(msg.Timestamp)
+
+	if msg.Edited != nil {
+		if editedAt, err := slackx.TimestampToTime(msg.Edited.Timestamp); err == nil {
+			m.EditedAt = &editedAt
+			m.EditedBy = msg.Edited.User
+		}
+	}
+
+	return m
+}
+
+// reportSnapshotText formats the snapshot as shown to staff. Texts are truncated to keep the message readable,
+// and within the Block Kit limits; the whole snapshot is kept in the case.
+func reportSnapshotText(snapshot *reports.Snapshot) string {
+	if snapshot == nil {
+		return ":warning: The reported message could not be captured. It may have been deleted already."
+	}
+
+	var sb strings.Builder
+	msg := snapshot.Message
+	_, _ = fmt.Fprintf(&sb, "*Reported message* (posted on %s", msg.PostedAt.Format("2006-01-02 15:04"))
+	if msg.EditedAt != nil {
+		_, _ = fmt.Fprintf(&sb, ", edited on %s", msg.EditedAt.Format("2006-01-02 15:04"))
+	}
+	_, _ = fmt.Fprintf(&sb, "):\n%s\n", quote(truncate(msg.Text, 1000)))
+
+	if len(snapshot.Context) > 1 {
+		_, _ = sb.WriteString("*Context*:\n")
+		for _, m := range snapshot.Context {
+			marker := "•"
+			if m.Timestamp == msg.Timestamp {
+				marker = ":arrow_right:"
+			}
+			_, _ = fmt.Fprintf(&sb, "%s <@%s> (%s): %s\n", marker, m.Author, m.PostedAt.Format("15:04"), strings.ReplaceAll(truncate(m.Text, 200), "\n", " "))
+		}
+	}
+
+	return sb.String()
+}
+
+func quote(text string) string {
+	return "> " + strings.ReplaceAll(text, "\n", "\n> ")
+}
+
+func truncate(text string, limit int) string {
+	runes := []rune(text)
+	if len(runes) <= limit {
+		return text
+	}
+
+	return string(runes[:limit]) + "…"
+}
+
+func reportCaseBlocks(c reports.Case) []slack.Block {
+	author := "a member"
+	if c.MessageAuthor != "" {
+		author = fmt.Sprintf("<@%s>", c.MessageAuthor)
+	}
+
+	reporter := fmt.Sprintf("<@%s>", c.Reporter)
+	if c.Anonymous {
+		reporter = "An anonymous member"
+	}
+
+	text := fmt.Sprintf("*Report #%d*: %s reported a <%s|message> by %s in <#%s>:\n- *Reason*: %s\n- *Feeling Scale*: %s of 5",
+		c.ID,
+		reporter,
+		slackx.LinkToMessage(c.Channel, c.MessageTimestamp),
+		author,
+		c.Channel,
+		c.Reason,
+		c.Scale,
+	)
+
+	blocks := []slack.Block{
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, text, false, false), nil, nil),
+		slack.NewSectionBlock(slack.NewTextBlockObject(slack.MarkdownType, reportSnapshotText(c.Snapshot), false, false), nil, nil),
+		slack.NewContextBlock("", slack.NewTextBlockObject(slack.MarkdownType, reportCaseStatus(c), false, false)),
+	}
+
+	if c.Status == reports.StatusResolved {
+		return blocks
+	}
+
+	value := strconv.Itoa(c.ID)
+	var buttons []slack.BlockElement
+	if c.Status == reports.StatusOpen {
+		buttons = append(buttons, slack.NewButtonBlockElement(actionReportTake, value, slack.NewTextBlockObject(slack.PlainTextType, "Take it", false, false)))
+	}
+
+	resolve := slack.NewButtonBlockElement(actionReportResolve, value, slack.NewTextBlockObject(slack.PlainTextType, "Resolve", false, false))
+	resolve.Style = slack.StylePrimary
+	buttons = append(buttons, resolve)
+
+	if !c.HasEvent(reports.EventMessageDeleted) {
+		deleteMessage := slack.NewButtonBlockElement(actionReportDeleteMessage, value, slack.NewTextBlockObject(slack.PlainTextType, "Delete message", false, false))
+		deleteMessage.Style = slack.StyleDanger
+		deleteMessage.Confirm = slack.NewConfirmationBlockObject(
+			slack.NewTextBlockObject(slack.PlainTextType, "Delete message", false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, "The reported message is going to be deleted permanently.", false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, "Delete", false, false),
+			slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		)
+		buttons = append(buttons, deleteMessage)
+	}
+
+	if c.MessageAuthor != "" {
+		buttons = append(buttons, slack.NewButtonBlockElement(actionReportWarnUser, value, slack.NewTextBlockObject(slack.PlainTextType, "Warn user", false, false)))
+	}
+
+	return append(blocks, slack.NewActionBlock("report_case", buttons...))
+}
+
+// reportCaseStatus describes the case status and the actions taken so far.
+func reportCaseStatus(c reports.Case) string {
+	lines := []string{fmt.Sprintf("*%s*", reportStatusLabels[c.Status])}
+	for _, e := range c.Events {
+		switch e.Type {
+		case reports.EventAssigned:
+			lines = append(lines, fmt.Sprintf("Taken by <@%s> on %s", e.By, e.At.Format("2006-01-02 15:04")))
+		case reports.EventMessageDeleted:
+			lines = append(lines, fmt.Sprintf(":wastebasket: Message deleted by <@%s>", e.By))
+		case reports.EventUserWarned:
+			lines = append(lines, fmt.Sprintf(":warning: User warned by <@%s>", e.By))
+		case reports.EventResolved:
+			lines = append(lines, fmt.Sprintf("Resolved by <@%s> on %s: %s", e.By, e.At.Format("2006-01-02 15:04"), e.Note))
+		case reports.EventReporterRevealed:
+			lines = append(lines, fmt.Sprintf(":eyes: Reporter revealed to <@%s>", e.By))
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// handleReportAction handles the buttons of the report notification.
+// Resolving and warning the user open a modal, while the rest of actions are applied right away.
+func handleReportAction(botContext Context, message slack.InteractionCallback, action *slack.BlockAction) {
+	if !botContext.IsStaff(message.User.ID) {
+		if resp, err := botContext.Client.OpenView(message.TriggerID, userNotAllowedModal()); err != nil {
+			logModalError(err, resp)
+		}
+		return
+	}
+
+	id, err := strconv.Atoi(action.Value)
+	if err != nil {
+		log.Printf("[ERROR] Invalid report case ID %q", action.Value)
+		return
+	}
+
+	switch action.ActionID {
+	case actionReportTake:
+		updateReportCase(botContext, message.User.ID, func() (reports.Case, error) {
+			return botContext.Reports.Assign(id, message.User.ID, time.Now())
+		})
+	case actionReportDeleteMessage:
+		updateReportCase(botContext, message.User.ID, func() (reports.Case, error) {
+			c, err := botContext.Reports.Get(id)
+			if err != nil {
+				return c, err
+			}
+			_, _, err = botContext.AdminClient.DeleteMessage(c.Channel, c.MessageTimestamp)
+			Audit(botContext, audit.Entry{Actor: message.User.ID, Action: "report_delete_message", Target: slackx.LinkToMessage(c.Channel, c.MessageTimestamp), Params: map[string]string{"report": strconv.Itoa(id)}}, err)
+			if err != nil {
+				return c, fmt.Errorf("deleting the reported message: %w", err)
+			}
+
+			return botContext.Reports.Record(id, reports.Event{Type: reports.EventMessageDeleted, By: message.User.ID, At: time.Now()})
+		})
+	case actionReportResolve, actionReportWarnUser:
+		modal := generateReportCaseModal(action.ActionID, botContext.Config.Links.COC)
+		modal.PrivateMetadata = action.Value // persist the case ID across submission
+		if resp, err := botContext.Client.OpenView(message.TriggerID, modal); err != nil {
+			logModalError(err, resp)
+		}
+	}
+}
+
+// handleReportModalSubmission handles the submission of the resolution and warning modals.
+func handleReportModalSubmission(botContext Context, w http.ResponseWriter, message slack.InteractionCallback) {
+	// We early set the Content-Type header for any response. This is important.
+	w.Header().Set("Content-Type", "application/json")
+
+	if !botContext.IsStaff(message.User.ID) {
+		_ = json.NewEncoder(w).Encode(
+			slack.NewErrorsViewSubmissionResponse(map[string]string{"text": "You are not allowed to handle reports."}),
+		)
+		return
+	}
+
+	id, err := strconv.Atoi(strings.Trim(message.View.PrivateMetadata, `"`)) // For some reason, slack adds an extra double quote
+	if err != nil {
+		log.Printf("[ERROR] Invalid report case ID %q", message.View.PrivateMetadata)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	text := strings.TrimSpace(message.View.State.Values["text"]["text"].Value)
+	_ = json.NewEncoder(w).Encode(slack.NewClearViewSubmissionResponse())
+
+	switch message.View.CallbackID {
+	case actionReportResolve:
+		go updateReportCase(botContext, message.User.ID, func() (reports.Case, error) {
+			c, err := botContext.Reports.Resolve(id, message.User.ID, text, time.Now())
+			if err != nil {
+				return c, err
+			}
+
+			reporter, err := reportReporter(botContext, c)
+			if err != nil {
+				log.Printf("[ERROR] Failed to get the reporter of report case #%d: %s", c.ID, err)
+				return c, nil
+			}
+			_ = slackx.Send(botContext.Client, "", reporter, reportResolvedMessage(c), false)
+
+			return c, nil
+		})
+	case actionReportWarnUser:
+		go updateReportCase(botContext, message.User.ID, func() (reports.Case, error) {
+			c, err := botContext.Reports.Get(id)
+			if err != nil {
+				return c, err
+			}
+			if err := slackx.Send(botContext.Client, "", c.MessageAuthor, text, false); err != nil {
+				return c, fmt.Errorf("warning the user: %w", err)
+			}
+
+			RecordModerationEvent(botContext, moderation.Record{User: c.MessageAuthor, Kind: moderation.KindWarning, Channel: c.Channel, Detail: fmt.Sprintf("case #%d", c.ID)})
+
+			return botContext.Reports.Record(id, reports.Event{Type: reports.EventUserWarned, By: message.User.ID, Note: text, At: time.Now()})
+		})
+	}
+}
+
+// updateReportCase applies the change to the case and refreshes the staff notification.
+// Errors are reported to the staff member as ephemeral messages in the staff channel.
+func updateReportCase(botContext Context, staff string, change func() (reports.Case, error)) {
+	c, err := change()
+	if err != nil {
+		msg := fmt.Sprintf("Report #%d could not be updated: %s", c.ID, err)
+		if errors.Is(err, reports.ErrCaseResolved) {
+			msg = fmt.Sprintf("Report #%d was already resolved by <@%s>.", c.ID, c.AssignedTo)
+		}
+		log.Printf("[ERROR] %s", msg)
+		_ = slackx.SendEphemeral(botContext.Client, "", botContext.Config.Channels.Staff, staff, msg)
+		return
+	}
+
+	refreshReportNotification(botContext, c)
+}
+
+// refreshReportNotification updates the staff notification of the case with its current state.
+func refreshReportNotification(botContext Context, c reports.Case) {
+	if _, _, _, err := botContext.Client.UpdateMessage(c.NotificationChannel, c.NotificationTimestamp, slack.MsgOptionText(fmt.Sprintf("Message report #%d", c.ID), false), slack.MsgOptionBlocks(reportCaseBlocks(c)...)); err != nil {
+		log.Printf("[WARN] Failed to update notification of report case #%d: %s", c.ID, err)
+	}
+}
+
+// reportReporter returns the user ID of the reporter, decrypting it for anonymous reports.
+// It must never be shown to staff members, except through the audited reveal command (see RevealReporter).
+func reportReporter(botContext Context, c reports.Case) (string, error) {
+	if !c.Anonymous {
+		return c.Reporter, nil
+	}
+
+	if botContext.ReportCipher == nil {
+		return "", errors.New("no encryption key configured")
+	}
+
+	return botContext.ReportCipher.Decrypt(c.EncryptedReporter)
+}
+
+// RevealReporter returns the user ID of the reporter of an anonymous report case.
+// Every reveal is recorded in the case history and announced in the staff channel along with the reason.
+func RevealReporter(botContext Context, id int, by, reason string) (string, error) {
+	if strings.TrimSpace(reason) == "" {
+		return "", errors.New("a reason is required to reveal a reporter")
+	}
+
+	c, err := botContext.Reports.Get(id)
+	if err != nil {
+		return "", err
+	}
+	if !c.Anonymous {
+		return "", fmt.Errorf("report #%d is not anonymous", id)
+	}
+
+	reporter, err := reportReporter(botContext, c)
+	if err != nil {
+		return "", err
+	}
+
+	c, err = botContext.Reports.Audit(id, reports.Event{Type: reports.EventReporterRevealed, By: by, Note: reason, At: time.Now()})
+	if err != nil {
+		return "", fmt.Errorf("recording the reveal: %w", err)
+	}
+	refreshReportNotification(botContext, c)
+
+	// Announced to staff below, so there is no need to mirror it.
+	Audit(botContext, audit.Entry{Actor: by, Action: "reports_reveal", Target: fmt.Sprintf("report #%d", id), Params: map[string]string{"reason": reason}}, nil)
+	_ = slackx.Send(botContext.Client, "", botContext.Config.Channels.Staff, fmt.Sprintf(":eyes: <@%s> revealed the reporter of the anonymous report #%d. Reason: %s", by, id, reason), false)
+
+	return reporter, nil
+}
+
+// reportResolvedMessage returns the message sent to the reporter once the case is resolved.
+func reportResolvedMessage(c reports.Case) string {
+	return fmt.Sprintf("Your <%s|message report> (#%d) has been resolved by the Staff. Thanks for helping us keep BcnEng a safe place!\n*Notes*: %s",
+		slackx.LinkToMessage(c.Channel, c.MessageTimestamp),
+		c.ID,
+		c.Resolution,
+	)
+}
+
+func generateReportCaseModal(callbackID, cocLink string) slack.ModalViewRequest {
+	title, label, hint, placeholder, initial := "Resolve report", "Resolution notes", "They will be sent to the reporter.", "The message was removed and the author warned.", ""
+	if callbackID == actionReportWarnUser {
+		title, label, hint, placeholder = "Warn user", "Warning", "It will be sent to the author of the reported message via DM.", ""
+		initial = fmt.Sprintf("Hi! One of your messages has been reported for not following our Code of Conduct (%s). Please review it and keep the conversation respectful.", cocLink)
+	}
+
+	input := slack.NewPlainTextInputBlockElement(slack.NewTextBlockObject(slack.PlainTextType, placeholder, false, false), "text")
+	input.Multiline = true
+	input.InitialValue = initial
+	if placeholder == "" {
+		input.Placeholder = nil
+	}
+	block := slack.NewInputBlock("text", slack.NewTextBlockObject(slack.PlainTextType, label, false, false), slack.NewTextBlockObject(slack.PlainTextType, hint, false, false), input)
+
+	return slack.ModalViewRequest{
+		Type:  slack.VTModal,
+		Title: slack.NewTextBlockObject(slack.PlainTextType, title, false, false),
+		Blocks: slack.Blocks{BlockSet: []slack.Block{
+			block,
+		}},
+		Submit:     slack.NewTextBlockObject(slack.PlainTextType, "Send", false, false),
+		CallbackID: callbackID,
+	}
+}
@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bcneng/candebot/internal/crypto"
+	"github.com/bcneng/candebot/internal/reports"
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportCaseBlocks(t *testing.T) {
+	c := reports.Case{
+		ID:               7,
+		Status:           reports.StatusOpen,
+		Reporter:         "UREPORTER",
+		Reason:           "Insults",
+		Scale:            "4",
+		Channel:          "CGENERAL",
+		MessageTimestamp: "1.1",
+		MessageAuthor:    "UAUTHOR",
+	}
+
+	actionIDs := func(blocks []slack.Block) []string {
+		actions, ok := blocks[len(blocks)-1].(*slack.ActionBlock)
+		if !ok {
+			return nil
+		}
+
+		var ids []string
+		for _, e := range actions.Elements.ElementSet {
+			ids = append(ids, e.(*slack.ButtonBlockElement).ActionID)
+		}
+		return ids
+	}
+
+	t.Run("open case", func(t *testing.T) {
+		blocks := reportCaseBlocks(c)
+		require.Contains(t, blocks[0].(*slack.SectionBlock).Text.Text, "*Report #7*: <@UREPORTER> reported a")
+		require.Equal(t, []string{actionReportTake, actionReportResolve, actionReportDeleteMessage, actionReportWarnUser}, actionIDs(blocks))
+	})
+
+	t.Run("acknowledged case with the message deleted", func(t *testing.T) {
+		acknowledged := c
+		acknowledged.Status = reports.StatusAcknowledged
+		acknowledged.Events = []reports.Event{
+			{Type: reports.EventAssigned, By: "USTAFF", At: time.Now()},
+			{Type: reports.EventMessageDeleted, By: "USTAFF", At: time.Now()},
+		}
+
+		blocks := reportCaseBlocks(acknowledged)
+		require.Equal(t, []string{actionReportResolve, actionReportWarnUser}, actionIDs(blocks))
+		require.Contains(t, reportCaseStatus(acknowledged), "Message deleted by <@USTAFF>")
+	})
+
+	t.Run("resolved case", func(t *testing.T) {
+		resolved := c
+		resolved.Status = reports.StatusResolved
+		require.Nil(t, actionIDs(reportCaseBlocks(resolved)))
+	})
+}
+
+func TestAnonymousReports(t *testing.T) {
+	cipher, err := crypto.NewCipher(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", crypto.KeySize))))
+	require.NoError(t, err)
+	store, err := reports.NewStore("")
+	require.NoError(t, err)
+	botContext := Context{Reports: store, ReportCipher: cipher}
+
+	encrypted, err := cipher.Encrypt("UREPORTER")
+	require.NoError(t, err)
+	anonymous, err := store.Open(reports.Case{Anonymous: true, EncryptedReporter: encrypted, Channel: "C1", MessageTimestamp: "1.1", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	public, err := store.Open(reports.Case{Reporter: "UREPORTER", Channel: "C1", MessageTimestamp: "1.1", CreatedAt: time.Now()})
+	require.NoError(t, err)
+
+	text := reportCaseBlocks(anonymous)[0].(*slack.SectionBlock).Text.Text
+	require.Contains(t, text, "An anonymous member reported")
+	require.NotContains(t, text, "UREPORTER")
+
+	reporter, err := reportReporter(botContext, anonymous)
+	require.NoError(t, err)
+	require.Equal(t, "UREPORTER", reporter)
+
+	_, err = RevealReporter(botContext, anonymous.ID, "USTAFF", " ")
+	require.Error(t, err, "a reason is required")
+	_, err = RevealReporter(botContext, public.ID, "USTAFF", "Abuse follow-up")
+	require.Error(t, err, "only anonymous reports can be revealed")
+
+	_, err = reportReporter(Context{}, anonymous)
+	require.Error(t, err, "anonymous reporters can't be decrypted without the key")
+}
+
+func TestGenerateReportMessageModal(t *testing.T) {
+	require.Len(t, generateReportMessageModal(false).Blocks.BlockSet, 2)
+	require.Len(t, generateReportMessageModal(true).Blocks.BlockSet, 3, "the anonymous checkbox is only offered with an encryption key")
+}
+
+func TestReportSnapshotText(t *testing.T) {
+	require.Contains(t, reportSnapshotText(nil), "could not be captured")
+
+	postedAt := time.Date(2024, 5, 6, 10, 0, 0, 0, time.UTC)
+	editedAt := postedAt.Add(time.Minute)
+	reported := reports.SnapshotMessage{Author: "UAUTHOR", Text: "first line\nsecond line", Timestamp: "2.0", PostedAt: postedAt, EditedAt: &editedAt}
+	snapshot := &reports.Snapshot{
+		Message: reported,
+		Context: []reports.SnapshotMessage{
+			{Author: "UOTHER", Text: strings.Repeat("a", 300), Timestamp: "1.0", PostedAt: postedAt},
+			reported,
+		},
+	}
+
+	text := reportSnapshotText(snapshot)
+	require.Contains(t, text, "(posted on 2024-05-06 10:00, edited on 2024-05-06 10:01)")
+	require.Contains(t, text, "> first line\n> second line")
+	require.Contains(t, text, "• <@UOTHER> (10:00): "+strings.Repeat("a", 200)+"…")
+	require.Contains(t, text, ":arrow_right: <@UAUTHOR> (10:00): first line second line")
+}
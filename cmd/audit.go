@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/bcneng/candebot/bot"
+	"github.com/bcneng/candebot/internal/audit"
+	"github.com/bcneng/candebot/slackx"
+)
+
+// maxAuditEntries is the maximum number of entries the audit command lists.
+const maxAuditEntries = 100
+
+type Audit struct {
+	Actor  string `help:"Only actions performed by this member (mention), or by bot, api or cli"`
+	Action string `help:"Only actions of this type. E.g. echo, delete_thread or create_channel"`
+	Limit  int    `default:"20" help:"Maximum number of entries to list"`
+}
+
+func (a *Audit) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	if !ctx.IsStaff(slackCtx.User) && !ctx.CLI {
+		return errors.New("this action is only allowed to Staff members")
+	}
+	if a.Limit <= 0 || a.Limit > maxAuditEntries {
+		return fmt.Errorf("the limit should be between 1 and %d", maxAuditEntries)
+	}
+
+	actor := a.Actor
+	if user, ok := slackx.ParseUserMention(actor); ok {
+		actor = user
+	}
+
+	entries := ctx.AuditLog.Recent(a.Limit, audit.Filter{Actor: actor, Action: a.Action})
+	return reply(cliCtx, ctx, slackCtx, bot.FormatAuditEntries(entries))
+}
+
+// auditActor returns who is running the command, for the audit log.
+func auditActor(ctx bot.Context, slackCtx bot.SlackContext) string {
+	if ctx.CLI {
+		return audit.ActorCLI
+	}
+
+	return slackCtx.User
+}
@@ -15,6 +15,11 @@ type CLI struct {
 	Candebirthday CandeBirthday `cmd:"" help:"Days until @sdecandelario birthday!"`
 	Echo          Echo          `cmd:"" help:"Sends a message from the bot user" placeholder:"echo #general Hi folks!"`
 	Contest       Contest       `cmd:"" help:"Runs a contest on Twitter"`
+	Jobs          Jobs          `cmd:"" help:"Job board alerts and tools"`
+	Reports       Reports       `cmd:"" help:"Message reports tools for Staff members"`
+	Mod           Mod           `cmd:"" help:"Moderation tools for Staff members"`
+	Audit         Audit         `cmd:"" help:"Lists the latest privileged actions performed through the bot (Staff only)"`
+	Inclusion     Inclusion     `cmd:"" help:"Inclusive language tools for Staff members"`
 	Help          Help          `cmd:""`
 }
 
@@ -9,6 +9,7 @@ import (
 	"net/http"
 
 	"github.com/bcneng/candebot/bot"
+	"github.com/bcneng/candebot/internal/audit"
 	"github.com/bcneng/candebot/slackx"
 )
 
@@ -35,6 +36,13 @@ func (c *Contest) Run(ctx bot.Context, slackCtx bot.SlackContext) error {
 	r.URL.RawQuery = q.Encode()
 
 	resp, err := http.DefaultClient.Do(r)
+	bot.Audit(ctx, audit.Entry{
+		Actor:    auditActor(ctx, slackCtx),
+		Action:   "contest",
+		Target:   c.TweetID,
+		Params:   map[string]string{"pick": c.Pick, "account_to_follow": c.AccountToFollow},
+		Severity: audit.SeverityHigh,
+	}, err)
 	if err != nil {
 		_ = slackx.SendEphemeral(ctx.Client, slackCtx.ThreadTimestamp, slackCtx.Channel, slackCtx.User, "error making request to twitter-contest")
 		return err
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/bcneng/candebot/bot"
+	"github.com/bcneng/candebot/internal/audit"
 
 	"github.com/bcneng/candebot/slackx"
 )
@@ -38,7 +39,15 @@ func (e *Echo) Run(ctx bot.Context, slackCtx bot.SlackContext) error {
 		return err
 	}
 
-	if err := slackx.Send(ctx.Client, "", channelID, e.Message, false); err != nil {
+	err = slackx.Send(ctx.Client, "", channelID, e.Message, false)
+	bot.Audit(ctx, audit.Entry{
+		Actor:    auditActor(ctx, slackCtx),
+		Action:   "echo",
+		Target:   fmt.Sprintf("<#%s>", channelID),
+		Params:   map[string]string{"message": e.Message},
+		Severity: audit.SeverityHigh,
+	}, err)
+	if err != nil {
 		_ = slackx.SendEphemeral(ctx.Client, slackCtx.ThreadTimestamp, slackCtx.Channel, slackCtx.User, fmt.Sprintf("Error sending message. Error: %s", err.Error()))
 		return err
 	}
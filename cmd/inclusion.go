@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/bcneng/candebot/bot"
+)
+
+type Inclusion struct {
+	Stats InclusionStats `cmd:"" help:"Lists the inclusive language rules by number of matches, with their false positive ratio and top channels (Staff only)"`
+	Test  InclusionTest  `cmd:"" help:"Shows the inclusive language rules matching a text, and the reply members would get (Staff only)" placeholder:"inclusion test hey guys"`
+}
+
+type InclusionStats struct {
+	Limit int `default:"20" help:"Maximum number of rules to list"`
+}
+
+func (i *InclusionStats) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	if !ctx.IsStaff(slackCtx.User) && !ctx.CLI {
+		return errors.New("this action is only allowed to Staff members")
+	}
+	if i.Limit <= 0 {
+		return errors.New("the limit should be positive")
+	}
+
+	stats := ctx.InclusionStats.All()
+	if len(stats) > i.Limit {
+		stats = stats[:i.Limit]
+	}
+
+	return reply(cliCtx, ctx, slackCtx, bot.FormatInclusionStats(stats))
+}
+
+type InclusionTest struct {
+	Language string   `help:"Language assumed for the text if it can't be detected: en, es or ca"`
+	Text     []string `arg:"" help:"Text to check"`
+}
+
+func (i *InclusionTest) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	if !ctx.IsStaff(slackCtx.User) && !ctx.CLI {
+		return errors.New("this action is only allowed to Staff members")
+	}
+
+	text := strings.Join(i.Text, " ")
+	return reply(cliCtx, ctx, slackCtx, bot.FormatInclusionTest(ctx, text, i.Language))
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/bcneng/candebot/bot"
+	"github.com/bcneng/candebot/internal/jobs"
+	"github.com/bcneng/candebot/slackx"
+)
+
+type Jobs struct {
+	Digest        JobsDigest        `cmd:"" help:"Posts the digest of last week's job posts (Staff only)"`
+	Subscribe     JobsSubscribe     `cmd:"" help:"Get a DM for every new job post matching your criteria" placeholder:"jobs subscribe golang backend --location=Barcelona --min-salary=50"`
+	Subscriptions JobsSubscriptions `cmd:"" help:"Lists your job alerts"`
+	Unsubscribe   JobsUnsubscribe   `cmd:"" help:"Removes one of your job alerts, or all of them"`
+	Stats         JobsStats         `cmd:"" help:"Shows the job board stats (Staff only)"`
+}
+
+type JobsDigest struct {
+	DryRun bool `help:"Only shows you the digest, without posting it"`
+}
+
+func (d *JobsDigest) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	if !ctx.IsStaff(slackCtx.User) && !ctx.CLI {
+		return errors.New("this action is only allowed to Staff members")
+	}
+
+	if d.DryRun {
+		return reply(cliCtx, ctx, slackCtx, bot.JobsDigest(ctx, time.Now()).Format())
+	}
+
+	if err := bot.SendJobsDigest(ctx, time.Now()); err != nil {
+		_ = slackx.SendEphemeral(ctx.Client, slackCtx.ThreadTimestamp, slackCtx.Channel, slackCtx.User, fmt.Sprintf("Error sending the digest. Error: %s", err.Error()))
+		return err
+	}
+
+	return slackx.SendEphemeral(ctx.Client, slackCtx.ThreadTimestamp, slackCtx.Channel, slackCtx.User, "Digest sent successfully!")
+}
+
+type JobsSubscribe struct {
+	Keywords  []string `arg:"" optional:"" help:"Words that must appear in the role or company"`
+	Location  string   `help:"Location of the job, e.g. Barcelona or Remote"`
+	MinSalary int      `help:"Minimum yearly salary, in thousands"`
+	Currency  string   `help:"Currency of the minimum salary" default:"EUR"`
+}
+
+func (s *JobsSubscribe) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	currency := strings.ToUpper(s.Currency)
+	if !ctx.Config.Jobs.HasCurrency(currency) {
+		return fmt.Errorf("currency should be one of %s", strings.Join(ctx.Config.Jobs.Currencies, ", "))
+	}
+
+	sub, err := ctx.JobSubscriptions.Subscribe(jobs.Subscription{
+		User:      slackCtx.User,
+		Keywords:  s.Keywords,
+		Location:  s.Location,
+		MinSalary: s.MinSalary,
+		Currency:  currency,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return reply(cliCtx, ctx, slackCtx, fmt.Sprintf(":bell: Job alert `%s` created (%s). You will get a DM for every new matching job post.", sub.ID, sub))
+}
+
+type JobsSubscriptions struct{}
+
+func (s *JobsSubscriptions) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	subs := ctx.JobSubscriptions.List(slackCtx.User)
+	if len(subs) == 0 {
+		return reply(cliCtx, ctx, slackCtx, "You have no job alerts. Create one with `jobs subscribe`.")
+	}
+
+	var sb strings.Builder
+	_, _ = sb.WriteString("Your job alerts:\n")
+	for _, sub := range subs {
+		_, _ = fmt.Fprintf(&sb, "• `%s`: %s\n", sub.ID, sub)
+	}
+	_, _ = sb.WriteString("Use `jobs unsubscribe <id>` to remove one of them, or `jobs unsubscribe --all` to remove them all.")
+
+	return reply(cliCtx, ctx, slackCtx, sb.String())
+}
+
+type JobsUnsubscribe struct {
+	ID  string `arg:"" optional:"" help:"ID of the job alert, as shown by jobs subscriptions"`
+	All bool   `help:"Removes all your job alerts"`
+}
+
+func (u *JobsUnsubscribe) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	if u.ID == "" && !u.All {
+		return errors.New("the ID of the job alert (or --all) is required")
+	}
+
+	id := u.ID
+	if u.All {
+		id = ""
+	}
+
+	removed, err := ctx.JobSubscriptions.Unsubscribe(slackCtx.User, id)
+	if err != nil {
+		return err
+	}
+	if removed == 0 {
+		return errors.New("no job alert found. Use `jobs subscriptions` to list yours")
+	}
+
+	return reply(cliCtx, ctx, slackCtx, fmt.Sprintf("%d job alert(s) removed.", removed))
+}
+
+type JobsStats struct {
+	Year int `help:"Only considers the posts published in this year"`
+}
+
+func (s *JobsStats) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	if !ctx.IsStaff(slackCtx.User) && !ctx.CLI {
+		return errors.New("this action is only allowed to Staff members")
+	}
+
+	return reply(cliCtx, ctx, slackCtx, bot.JobsStats(ctx, s.Year).Format())
+}
+
+// reply sends the message as ephemeral to the user running the command, or prints it when running from CLI.
+func reply(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext, msg string) error {
+	if ctx.CLI {
+		_, err := cliCtx.Stdout.Write([]byte(msg + "\n"))
+		return err
+	}
+
+	return slackx.SendEphemeral(ctx.Client, slackCtx.ThreadTimestamp, slackCtx.Channel, slackCtx.User, msg)
+}
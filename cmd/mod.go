@@ -0,0 +1,142 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/bcneng/candebot/bot"
+	"github.com/bcneng/candebot/internal/moderation"
+	"github.com/bcneng/candebot/slackx"
+)
+
+type Mod struct {
+	History  ModHistory  `cmd:"" help:"Lists the moderation events of a member: reports, rate limit deletions and warnings (Staff only)" placeholder:"mod history @john"`
+	Warn     ModWarn     `cmd:"" help:"Sends a formal warning to a member about a Code of Conduct section (Staff only)" placeholder:"mod warn @john spam Promoted a course in 5 channels"`
+	Timeout  ModTimeout  `cmd:"" help:"Deletes the new top level messages of a member for some hours (Staff only)" placeholder:"mod timeout @john 24 Flooding #general"`
+	Restrict ModRestrict `cmd:"" help:"Deletes every new message of a member until lifted (Staff only)" placeholder:"mod restrict @john Harassment"`
+	Lift     ModLift     `cmd:"" help:"Lifts the timeout or restriction of a member (Staff only)" placeholder:"mod lift @john"`
+}
+
+type ModHistory struct {
+	User string `arg:"" help:"Member mention"`
+}
+
+func (m *ModHistory) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	user, err := modTarget(ctx, slackCtx, m.User)
+	if err != nil {
+		return err
+	}
+
+	records := ctx.ModerationHistory.ForUser(user)
+	return reply(cliCtx, ctx, slackCtx, bot.FormatModerationHistory(user, records, ctx.Config.Moderation.AlertWindowDays, time.Now()))
+}
+
+type ModWarn struct {
+	User    string   `arg:"" help:"Member mention"`
+	Section string   `arg:"" help:"Code of Conduct section key"`
+	Note    []string `arg:"" optional:"" help:"Notes for the member"`
+}
+
+func (m *ModWarn) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	user, err := modTarget(ctx, slackCtx, m.User)
+	if err != nil {
+		return err
+	}
+
+	if err := bot.WarnUser(ctx, slackCtx.User, user, m.Section, strings.Join(m.Note, " ")); err != nil {
+		return err
+	}
+
+	return reply(cliCtx, ctx, slackCtx, fmt.Sprintf("<@%s> has been warned.", user))
+}
+
+type ModTimeout struct {
+	User   string   `arg:"" help:"Member mention"`
+	Hours  int      `arg:"" help:"Duration of the timeout in hours"`
+	Reason []string `arg:"" optional:"" help:"Reason of the timeout"`
+}
+
+func (m *ModTimeout) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	user, err := modTarget(ctx, slackCtx, m.User)
+	if err != nil {
+		return err
+	}
+	if m.Hours <= 0 {
+		return errors.New("the timeout should last at least 1 hour")
+	}
+
+	now := time.Now()
+	err = bot.ImposeSanction(ctx, moderation.Sanction{
+		User:   user,
+		Type:   moderation.SanctionTimeout,
+		Reason: strings.Join(m.Reason, " "),
+		By:     slackCtx.User,
+		Since:  now,
+		Until:  now.Add(time.Duration(m.Hours) * time.Hour),
+	})
+	if err != nil {
+		return err
+	}
+
+	return reply(cliCtx, ctx, slackCtx, fmt.Sprintf("<@%s> has been timed out for %d hours.", user, m.Hours))
+}
+
+type ModRestrict struct {
+	User   string   `arg:"" help:"Member mention"`
+	Reason []string `arg:"" optional:"" help:"Reason of the restriction"`
+}
+
+func (m *ModRestrict) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	user, err := modTarget(ctx, slackCtx, m.User)
+	if err != nil {
+		return err
+	}
+
+	err = bot.ImposeSanction(ctx, moderation.Sanction{
+		User:   user,
+		Type:   moderation.SanctionRestriction,
+		Reason: strings.Join(m.Reason, " "),
+		By:     slackCtx.User,
+		Since:  time.Now(),
+	})
+	if err != nil {
+		return err
+	}
+
+	return reply(cliCtx, ctx, slackCtx, fmt.Sprintf("<@%s> has been restricted until lifted with `mod lift`.", user))
+}
+
+type ModLift struct {
+	User string `arg:"" help:"Member mention"`
+}
+
+func (m *ModLift) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	user, err := modTarget(ctx, slackCtx, m.User)
+	if err != nil {
+		return err
+	}
+
+	if err := bot.LiftSanction(ctx, slackCtx.User, user); err != nil {
+		return err
+	}
+
+	return reply(cliCtx, ctx, slackCtx, fmt.Sprintf("The sanction of <@%s> has been lifted.", user))
+}
+
+// modTarget checks the caller is a staff member, and returns the ID of the mentioned member.
+func modTarget(ctx bot.Context, slackCtx bot.SlackContext, mention string) (string, error) {
+	if !ctx.IsStaff(slackCtx.User) && !ctx.CLI {
+		return "", errors.New("this action is only allowed to Staff members")
+	}
+
+	user, ok := slackx.ParseUserMention(mention)
+	if !ok {
+		return "", fmt.Errorf("%q is not a member mention", mention)
+	}
+
+	return user, nil
+}
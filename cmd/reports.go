@@ -0,0 +1,33 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/kong"
+
+	"github.com/bcneng/candebot/bot"
+)
+
+type Reports struct {
+	Reveal ReportsReveal `cmd:"" help:"Reveals the reporter of an anonymous report. Every reveal is audited (Staff only)" placeholder:"reports reveal 12 Follow up on abusive reports"`
+}
+
+type ReportsReveal struct {
+	ID     int      `arg:"" help:"Report number"`
+	Reason []string `arg:"" help:"Why the reporter needs to be revealed"`
+}
+
+func (r *ReportsReveal) Run(cliCtx *kong.Context, ctx bot.Context, slackCtx bot.SlackContext) error {
+	if !ctx.IsStaff(slackCtx.User) && !ctx.CLI {
+		return errors.New("this action is only allowed to Staff members")
+	}
+
+	reporter, err := bot.RevealReporter(ctx, r.ID, slackCtx.User, strings.Join(r.Reason, " "))
+	if err != nil {
+		return err
+	}
+
+	return reply(cliCtx, ctx, slackCtx, fmt.Sprintf("The anonymous report #%d was sent by <@%s>. This reveal has been recorded.", r.ID, reporter))
+}
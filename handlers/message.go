@@ -11,6 +11,8 @@ import (
 	"github.com/bcneng/candebot/bot"
 	"github.com/bcneng/candebot/cmd"
 	"github.com/bcneng/candebot/inclusion"
+	"github.com/bcneng/candebot/internal/audit"
+	"github.com/bcneng/candebot/internal/moderation"
 	"github.com/bcneng/candebot/internal/privacy"
 	"github.com/bcneng/candebot/slackx"
 	"github.com/newrelic/newrelic-telemetry-sdk-go/telemetry"
@@ -24,6 +26,15 @@ func MessageEventHandler(botCtx bot.Context, e slackevents.EventsAPIInnerEvent)
 		return nil
 	}
 
+	// Messages of sanctioned members are deleted before anything else, so they get no other reply
+	if event.SubType == "" && event.ChannelType != "im" {
+		if sanction, ok := bot.ActiveSanction(botCtx, event.User, event.Channel, event.ThreadTimeStamp != ""); ok {
+			msg := fmt.Sprintf("Your message has been deleted because you are under a %s imposed by the staff.", sanction.Type)
+			deleteMessage(botCtx, event, "sanction_message_deleted", msg)
+			return nil
+		}
+	}
+
 	if event.SubType == "" || event.SubType == "message_replied" {
 		// behaviors that apply to all messages posted by users both in channels or threads
 		go checkLanguage(botCtx, event)
@@ -56,9 +67,8 @@ func MessageEventHandler(botCtx bot.Context, e slackevents.EventsAPIInnerEvent)
 						"You can post again in approximately %s.",
 					waitDuration.Round(time.Second),
 				)
-				_ = slackx.SendEphemeral(botCtx.Client, event.ThreadTimeStamp, event.Channel, event.User, msg)
-
-				_, _, _ = botCtx.AdminClient.DeleteMessage(event.Channel, event.TimeStamp)
+				deleteMessage(botCtx, event, "rate_limit_message_deleted", msg)
+				bot.RecordModerationEvent(botCtx, moderation.Record{User: event.User, Kind: moderation.KindRateLimit, Channel: event.Channel})
 				return nil
 			}
 		}
@@ -74,7 +84,7 @@ func MessageEventHandler(botCtx bot.Context, e slackevents.EventsAPIInnerEvent)
 		// Users are allowed to only post messages in threads
 		if event.ThreadTimeStamp == "" {
 			log.Printf("Someone wrote a random message in %s and will be removed. %s %s", event.Channel, event.Text, event.TimeStamp)
-			_, _, _ = botCtx.AdminClient.DeleteMessage(event.Channel, event.TimeStamp)
+			deleteMessage(botCtx, event, "jobs_channel_message_deleted", "")
 			return nil
 		}
 	case botCtx.Config.Channels.Playground:
@@ -84,6 +94,22 @@ func MessageEventHandler(botCtx bot.Context, e slackevents.EventsAPIInnerEvent)
 	return nil
 }
 
+// deleteMessage deletes the message with admin rights, letting its author know why if a notice is given.
+// The deletion is recorded in the audit log under the given action.
+func deleteMessage(botCtx bot.Context, event *slackevents.MessageEvent, action, notice string) {
+	if notice != "" {
+		_ = slackx.SendEphemeral(botCtx.Client, event.ThreadTimeStamp, event.Channel, event.User, notice)
+	}
+
+	_, _, err := botCtx.AdminClient.DeleteMessage(event.Channel, event.TimeStamp)
+	bot.Audit(botCtx, audit.Entry{
+		Actor:  audit.ActorBot,
+		Action: action,
+		Target: slackx.LinkToMessage(event.Channel, event.TimeStamp),
+		Params: map[string]string{"user": event.User, "channel": event.Channel, "ts": event.TimeStamp},
+	}, err)
+}
+
 func botCommand(botCtx bot.Context, slackCtx bot.SlackContext) {
 	text := strings.TrimSpace(strings.TrimPrefix(slackCtx.Text, fmt.Sprintf("<@%s>", botCtx.Config.Bot.UserID)))
 	args := strings.Split(text, " ") // TODO strings.Split is not valid for quoted strings that contain spaces (E.g. echo command)
@@ -107,24 +133,59 @@ func botCommand(botCtx bot.Context, slackCtx bot.SlackContext) {
 }
 
 func checkLanguage(botCtx bot.Context, event *slackevents.MessageEvent) {
-	filter := inclusion.Filter(event.Text)
-	if filter == nil {
+	channelConfig := botCtx.InclusionChannels[event.Channel]
+	if channelConfig.Disabled {
+		return
+	}
+
+	rules := botCtx.InclusionRules
+	if channelConfig.Rules != nil {
+		rules = channelConfig.Rules
+	}
+
+	// Only prose is checked, leaving code, quotes, links and mentions out. Prose keeps the offsets of the
+	// message text, so the suggested rewrite is done over the whole message.
+	suggestion := rules.Suggest(slackx.Prose(event.Text), event.Channel, channelConfig.Language)
+	if suggestion == nil {
 		return
 	}
+	suggestion.Rewrite = inclusion.Rewrite(event.Text, suggestion.Matches)
 
-	// Send reply as Slack ephemeral message
-	_ = slackx.SendEphemeral(botCtx.Client, event.ThreadTimeStamp, event.Channel, event.User, filter.Reply)
+	// Skip the terms the user asked not to be reminded about
+	suggestion = bot.UnmutedSuggestion(botCtx, event.User, event.Text, suggestion)
+	if suggestion == nil {
+		return
+	}
 
-	// Sending metrics
-	botCtx.Harvester.RecordMetric(telemetry.Count{
-		Name: fmt.Sprintf("%s.%s", strings.ToLower(botCtx.Config.Bot.Name), "inclusion.message_filtered"),
-		Attributes: map[string]interface{}{
-			"channel": event.Channel,
-			"filter":  filter.Filter,
-		},
-		Value:     1,
-		Timestamp: time.Now(),
+	// Do not nudge the same user too often in the channel
+	if cooldown := time.Duration(channelConfig.CooldownMinutes) * time.Minute; cooldown > 0 && botCtx.NudgeCooldown != nil {
+		if !botCtx.NudgeCooldown.Allow(event.Channel, event.User, cooldown, time.Now()) {
+			return
+		}
+	}
+
+	// Send a single reply with all the matches as Slack ephemeral message
+	_ = bot.SendInclusionNudge(botCtx, event.ThreadTimeStamp, event.Channel, event.User, event.TimeStamp, event.Text, *suggestion)
+	bot.RecordModerationEvent(botCtx, moderation.Record{
+		User:    event.User,
+		Kind:    moderation.KindInclusion,
+		Channel: event.Channel,
+		Detail:  strings.Join(suggestion.Filters(), ", "),
 	})
+
+	// Sending metrics, one per match
+	for _, m := range suggestion.Matches {
+		botCtx.Harvester.RecordMetric(telemetry.Count{
+			Name: fmt.Sprintf("%s.%s", strings.ToLower(botCtx.Config.Bot.Name), "inclusion.message_filtered"),
+			Attributes: map[string]interface{}{
+				"channel":  event.Channel,
+				"filter":   m.Filter.Filter,
+				"language": m.Filter.Language,
+			},
+			Value:     1,
+			Timestamp: time.Now(),
+		})
+	}
 }
 
 func checkTracking(botCtx bot.Context, event *slackevents.MessageEvent) {
@@ -143,6 +204,7 @@ func checkTracking(botCtx bot.Context, event *slackevents.MessageEvent) {
 
 	warning := privacy.FormatWarningMessage(tracked)
 	_ = slackx.SendEphemeral(botCtx.Client, event.ThreadTimeStamp, event.Channel, event.User, warning)
+	bot.RecordModerationEvent(botCtx, moderation.Record{User: event.User, Kind: moderation.KindTracking, Channel: event.Channel})
 
 	if botCtx.Harvester != nil {
 		for _, t := range tracked {
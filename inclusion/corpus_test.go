@@ -0,0 +1,66 @@
+package inclusion
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pelletier/go-toml/v2"
+	"github.com/stretchr/testify/require"
+)
+
+// corpusFile is the format of the golden corpus files in testdata/corpus.
+type corpusFile struct {
+	Language string `toml:"language"`
+	Rules    []struct {
+		Pattern   string   `toml:"pattern"`
+		Positives []string `toml:"positives"`
+		Negatives []string `toml:"negatives"`
+	} `toml:"rules"`
+}
+
+// TestCorpus checks every built-in rule against its examples. Any rule starting to match a known negative, or
+// no longer matching a known positive, fails the test.
+func TestCorpus(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join("testdata", "corpus", "*.toml"))
+	require.NoError(t, err)
+	require.NotEmpty(t, paths)
+
+	covered := make(map[string]bool)
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		require.NoError(t, err)
+
+		var corpus corpusFile
+		require.NoError(t, toml.Unmarshal(data, &corpus), p)
+
+		for _, rule := range corpus.Rules {
+			covered[rule.Pattern] = true
+			t.Run(rule.Pattern, func(t *testing.T) {
+				require.NotEmpty(t, rule.Positives, "every rule needs positive examples")
+				require.NotEmpty(t, rule.Negatives, "every rule needs negative examples")
+
+				for _, text := range rule.Positives {
+					require.True(t, matchesRule(text, corpus.Language, rule.Pattern), "%q should match", text)
+				}
+				for _, text := range rule.Negatives {
+					require.False(t, matchesRule(text, corpus.Language, rule.Pattern), "%q should not match", text)
+				}
+			})
+		}
+	}
+
+	for _, f := range inclusiveFilters {
+		require.True(t, covered[f.Filter], "built-in rule %q has no examples in the corpus", f.Filter)
+	}
+}
+
+func matchesRule(text, language, pattern string) bool {
+	for _, m := range defaultRuleset.MatchIn(text, "", language) {
+		if m.Filter.Filter == pattern {
+			return true
+		}
+	}
+
+	return false
+}
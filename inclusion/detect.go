@@ -0,0 +1,100 @@
+package inclusion
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// stopwords are frequent words of each language, used to detect the language of a text.
+// Words shared by several languages count for all of them.
+var stopwords = map[string][]string{
+	LanguageEnglish: {
+		"the", "and", "is", "are", "was", "were", "you", "he", "she", "it", "we", "they", "this", "that", "these", "those",
+		"of", "to", "in", "on", "for", "with", "at", "by", "from", "have", "has", "had", "do", "does", "did", "not",
+		"but", "or", "if", "what", "which", "who", "how", "there", "here", "be", "been", "can", "will", "would", "just",
+		"my", "your", "our", "their", "an", "all", "so", "about", "hi", "hey", "thanks", "anyone", "some",
+	},
+	LanguageSpanish: {
+		"el", "la", "los", "las", "de", "del", "que", "y", "en", "un", "una", "es", "por", "con", "para", "no", "se",
+		"lo", "como", "pero", "sus", "su", "al", "este", "esta", "esto", "ese", "esa", "eso", "muy", "hay", "son",
+		"está", "están", "tiene", "yo", "tú", "él", "ella", "nosotros", "vosotros", "ellos", "también", "cuando",
+		"donde", "porque", "hola", "gracias", "alguien", "sí", "mi", "tu", "ya", "todo", "pues", "bueno",
+	},
+	LanguageCatalan: {
+		"el", "la", "els", "les", "de", "del", "que", "i", "en", "un", "una", "és", "per", "amb", "no", "es", "ho",
+		"com", "però", "seu", "seva", "al", "aquest", "aquesta", "això", "aquell", "molt", "hi", "són", "està",
+		"tinc", "té", "jo", "tu", "ell", "ella", "nosaltres", "vosaltres", "ells", "també", "quan", "on", "perquè",
+		"hola", "gràcies", "algú", "sí", "meu", "teu", "ja", "tot", "doncs", "bé", "ara", "fer", "fa",
+	},
+}
+
+// languageMarkers are characters only found in some of the languages.
+var languageMarkers = map[string][]string{
+	LanguageSpanish: {"ñ", "¿", "¡"},
+	LanguageCatalan: {"ç", "l·l", "à", "è", "ò", "ï"},
+}
+
+// stopwordLanguages indexes the languages of each stopword.
+var stopwordLanguages = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, w := range words {
+			index[w] = append(index[w], lang)
+		}
+	}
+
+	return index
+}()
+
+// minDetectionScore is the minimum score a language needs to be detected.
+const minDetectionScore = 2
+
+// DetectLanguages returns the languages the text is likely written in, the most likely first.
+// Several languages are returned when their scores are close, as it happens with mixed or short texts.
+// Returns nil if no language could be detected.
+func DetectLanguages(text string) []string {
+	text = strings.ToLower(text)
+
+	scores := make(map[string]int, len(stopwords))
+	words := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '·'
+	})
+	for _, w := range words {
+		for _, lang := range stopwordLanguages[w] {
+			scores[lang]++
+		}
+	}
+
+	for lang, markers := range languageMarkers {
+		for _, m := range markers {
+			if strings.Contains(text, m) {
+				scores[lang] += 2
+			}
+		}
+	}
+
+	var best int
+	for _, score := range scores {
+		if score > best {
+			best = score
+		}
+	}
+	if best < minDetectionScore {
+		return nil
+	}
+
+	var languages []string
+	for _, lang := range []string{LanguageEnglish, LanguageSpanish, LanguageCatalan} {
+		// Languages scoring at least 3/4 of the best one are considered too.
+		if scores[lang] >= minDetectionScore && scores[lang]*4 >= best*3 {
+			languages = append(languages, lang)
+		}
+	}
+
+	sort.SliceStable(languages, func(i, j int) bool {
+		return scores[languages[i]] > scores[languages[j]]
+	})
+
+	return languages
+}
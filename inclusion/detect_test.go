@@ -0,0 +1,33 @@
+package inclusion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDetectLanguages(t *testing.T) {
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{text: "Has anyone tried the new version of the library?", want: []string{LanguageEnglish}},
+		{text: "¿Alguien sabe si hay meetups de Go este mes?", want: []string{LanguageSpanish}},
+		{text: "Algú sap si avui hi ha algun esdeveniment a la ciutat?", want: []string{LanguageCatalan}},
+		{text: "És un noi simpàtic", want: []string{LanguageCatalan}},
+		{text: "LGTM", want: nil},
+		{text: "", want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			require.Equal(t, tt.want, DetectLanguages(tt.text))
+		})
+	}
+}
+
+func TestDetectLanguages_Close(t *testing.T) {
+	// Spanish and Catalan share many stopwords, so both are returned when close.
+	languages := DetectLanguages("la casa de la playa")
+	require.ElementsMatch(t, []string{LanguageSpanish, LanguageCatalan}, languages)
+}
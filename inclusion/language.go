@@ -2,76 +2,83 @@ package inclusion
 
 import (
 	"fmt"
-	"regexp"
-	"strings"
-	"unicode"
-
-	"golang.org/x/text/runes"
-	"golang.org/x/text/transform"
-	"golang.org/x/text/unicode/norm"
+	"log"
 )
 
 type InclusiveFilter struct {
-	Filter string // Supports regex
-	Reply  string
-	regex  *regexp.Regexp // do not fill. Just used for caching the regex once compiled.
+	Filter      string   `toml:"pattern" yaml:"pattern"` // Supports regex
+	Reply       string   `toml:"reply" yaml:"reply"`
+	Alternative string   `toml:"alternative" yaml:"alternative"` // Replaces the matched term when rewriting a message. Empty for no rewrite.
+	Language    string   `toml:"language" yaml:"language"`
+	Set         string   `toml:"set" yaml:"set"`               // Rule set the filter belongs to, so channels can choose which ones apply. See SetBuiltIn.
+	Severity    string   `toml:"severity" yaml:"severity"`     // Empty means SeverityMedium.
+	Channels    []string `toml:"channels" yaml:"channels"`     // Channels the filter is enabled in. Empty enables it everywhere.
+	Exemptions  []string `toml:"exemptions" yaml:"exemptions"` // Texts matching any of these patterns are not filtered. Supports regex.
+	// CaseSensitive matches the pattern as written. Otherwise texts are lowercased, so patterns must be lowercase.
+	// Meant for acronyms that are common words in lowercase.
+	CaseSensitive bool `toml:"case_sensitive" yaml:"case_sensitive"`
 }
 
-var conductLinks = "\nIn case of doubts please check our <https://bcneng.org/coc|Code of Conduct> and/or our <https://bcneng.org/netiquette|Netiquette> "
+// conductLinks are appended to replies, in the language of the filter. English is used by default.
+var conductLinks = map[string]string{
+	LanguageEnglish: "\nIn case of doubts please check our <https://bcneng.org/coc|Code of Conduct> and/or our <https://bcneng.org/netiquette|Netiquette> ",
+	LanguageSpanish: "\nEn caso de duda, consulta nuestro <https://bcneng.org/coc|Código de Conducta> y/o nuestra <https://bcneng.org/netiquette|Netiqueta> ",
+	LanguageCatalan: "\nEn cas de dubte, consulta el nostre <https://bcneng.org/coc|Codi de Conducta> i/o la nostra <https://bcneng.org/netiquette|Netiqueta> ",
+}
 
 var inclusiveFilters = []InclusiveFilter{
 	// When someone says, the bot replies (privately).
 	// English: Based on https://github.com/randsleadershipslack/documents-and-resources/blob/master/RandsInclusiveLanguage.tsv
-	{Filter: "you guys", Reply: "Instead of *guys*, perhaps you mean *pals*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "these guys", Reply: "Instead of *guys*, perhaps you mean *gang*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "my guys", Reply: "Instead of *guys*, perhaps you mean *crew*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "those guys", Reply: "Instead of *guys*, perhaps you mean *people*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "hey guys", Reply: "Instead of *guys*, perhaps you mean *y'all*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "hi guys", Reply: "Instead of *guys*, perhaps you mean *everyone*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "the guys", Reply: "Instead of *guys*, perhaps you mean *folks*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "guys", Reply: "Instead of *guys*, have you considered a more gender-neutral pronoun like *folks*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "CHWD", Reply: `Cisgender Hetero White Dude. But please consider using the full term "cisgender, heterosexual white man” or similar. That would both make it more approachable for those unfamiliar with this obscure initialism, and prevent reducing people down to initialisms.`},
-	{Filter: "URP", Reply: `Underrepresented person(s). But please consider using the full term "members of traditionally underrepresented groups" or similar; people don't like to be made into acronyms, _especially_ when they are already marginalized. See: en.wikipedia.org/wiki/Underrepresented_group`},
-	{Filter: "URPs", Reply: `Underrepresented person(s). But please consider using the full term "members of traditionally underrepresented groups" or similar; people don't like to be made into acronyms, _especially_ when they are already marginalized. See: en.wikipedia.org/wiki/Underrepresented_group`},
-	{Filter: "URM", Reply: `Underrepresented minorit(y|ies). But please consider using the full term "members of traditionally underrepresented groups" or similar; people don't like to be made into acronyms, _especially_ when they are already marginalized. See: en.wikipedia.org/wiki/Underrepresented_group`},
-	{Filter: "URG", Reply: `Underrepresented group(s). But please consider using the full term "members of traditionally underrepresented groups" or similar; people don't like to be made into acronyms, _especially_ when they are already marginalized. See: en.wikipedia.org/wiki/Underrepresented_group`},
-	{Filter: "crazy", Reply: "Using the word *crazy* is considered by some to be insensitive to sufferers of mental illness, maybe you mean *outrageous*, *unthinkable*, *nonsensical*, *incomprehensible*? Have you considered a different adjective like *ridiculous*? You can read more information about it at https://www.selfdefined.app/definitions/crazy/"},
-	{Filter: "insane", Reply: "The word *insane* is considered by some to be insensitive to sufferers of mental illness. Perhaps you mean *outrageous*, *unthinkable*, *nonsensical*, *incomprehensible*? Have you considered a different adjective like *ridiculous*? You can read more information about it at https://www.selfdefined.app/definitions/crazy/"},
-	{Filter: "slave", Reply: `If you are referring to a data replication strategy, please consider a term such as ""follower"" or ""replica"". You can read more information about it at https://www.selfdefined.app/definitions/master-slave/`},
+	{Filter: "you guys", Language: LanguageEnglish, Alternative: "you all", Reply: "Instead of *guys*, perhaps you mean *pals*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "these guys", Language: LanguageEnglish, Alternative: "these folks", Reply: "Instead of *guys*, perhaps you mean *gang*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "my guys", Language: LanguageEnglish, Alternative: "my crew", Reply: "Instead of *guys*, perhaps you mean *crew*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "those guys", Language: LanguageEnglish, Alternative: "those people", Reply: "Instead of *guys*, perhaps you mean *people*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "hey guys", Language: LanguageEnglish, Alternative: "hey y'all", Reply: "Instead of *guys*, perhaps you mean *y'all*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "hi guys", Language: LanguageEnglish, Alternative: "hi everyone", Reply: "Instead of *guys*, perhaps you mean *everyone*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "the guys", Language: LanguageEnglish, Alternative: "the folks", Reply: "Instead of *guys*, perhaps you mean *folks*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "guys", Language: LanguageEnglish, Alternative: "folks", Reply: "Instead of *guys*, have you considered a more gender-neutral pronoun like *folks*? You can read more information about it at https://www.dictionary.com/e/you-guys/... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "CHWD", Language: LanguageEnglish, CaseSensitive: true, Reply: `Cisgender Hetero White Dude. But please consider using the full term "cisgender, heterosexual white man” or similar. That would both make it more approachable for those unfamiliar with this obscure initialism, and prevent reducing people down to initialisms.`},
+	{Filter: "URP", Language: LanguageEnglish, CaseSensitive: true, Reply: `Underrepresented person(s). But please consider using the full term "members of traditionally underrepresented groups" or similar; people don't like to be made into acronyms, _especially_ when they are already marginalized. See: en.wikipedia.org/wiki/Underrepresented_group`},
+	{Filter: "URPs", Language: LanguageEnglish, CaseSensitive: true, Reply: `Underrepresented person(s). But please consider using the full term "members of traditionally underrepresented groups" or similar; people don't like to be made into acronyms, _especially_ when they are already marginalized. See: en.wikipedia.org/wiki/Underrepresented_group`},
+	{Filter: "URM", Language: LanguageEnglish, CaseSensitive: true, Reply: `Underrepresented minorit(y|ies). But please consider using the full term "members of traditionally underrepresented groups" or similar; people don't like to be made into acronyms, _especially_ when they are already marginalized. See: en.wikipedia.org/wiki/Underrepresented_group`},
+	{Filter: "URG", Language: LanguageEnglish, CaseSensitive: true, Reply: `Underrepresented group(s). But please consider using the full term "members of traditionally underrepresented groups" or similar; people don't like to be made into acronyms, _especially_ when they are already marginalized. See: en.wikipedia.org/wiki/Underrepresented_group`},
+	{Filter: "crazy", Language: LanguageEnglish, Alternative: "ridiculous", Reply: "Using the word *crazy* is considered by some to be insensitive to sufferers of mental illness, maybe you mean *outrageous*, *unthinkable*, *nonsensical*, *incomprehensible*? Have you considered a different adjective like *ridiculous*? You can read more information about it at https://www.selfdefined.app/definitions/crazy/"},
+	{Filter: "insane", Language: LanguageEnglish, Alternative: "ridiculous", Reply: "The word *insane* is considered by some to be insensitive to sufferers of mental illness. Perhaps you mean *outrageous*, *unthinkable*, *nonsensical*, *incomprehensible*? Have you considered a different adjective like *ridiculous*? You can read more information about it at https://www.selfdefined.app/definitions/crazy/"},
+	{Filter: "slave", Language: LanguageEnglish, Alternative: "replica", Reply: `If you are referring to a data replication strategy, please consider a term such as ""follower"" or ""replica"". You can read more information about it at https://www.selfdefined.app/definitions/master-slave/`},
 
 	// Spanish: Based on https://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf
-	{Filter: "discapacitad(a|o)", Reply: "Ante todo somos personas, y no queremos que se nos etiquete, puesto que la discapacidad es una característica más de todas las que se tiene, no lo único por lo que se debe reconocer.\nPor eso es importante anteponer la palabra *persona* y lo más aconsejable es utilizar el término *persona con discapacidad* y no *discapacitado*.\nMás info en https://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf"},
-	{Filter: "discapacitad(a|o) fisic(a|o)", Reply: "Ante todo somos personas, y no queremos que se nos etiquete, puesto que la discapacidad es una característica más de todas las que se tiene, no lo único por lo que se debe reconocer.\nPor eso es importante anteponer la palabra *persona* y lo más aconsejable es utilizar el término *persona con discapacidad* y no *discapacitada física*.\nMás info en https://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf"},
-	{Filter: "minusvalid(a|o)", Reply: "*Minusválido* es un término peyorativo y vulnera la dignidad de las personas con discapacidad, al atribuirse un nulo o reducido valor a una persona, o utilizarse generalmente con elevada carga negativa. Considera usar *persona con discapacidad*.\nMás info en Más info en https://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf"},
-	{Filter: "diversidad funcional", Reply: "COCEMFE considera que el término *diversidad funcional* es un eufemismo, cargado de condescendencia que genera confusión, inseguridad jurídica y rebaja la protección que todavía es necesaria. El término *discapacidad* es el que aglutina derechos reconocidos legalmente y que cuenta con el mayor respaldo social. Considera usarlo.\nMás info en Más info en https://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf"},
-	{Filter: "retrasad(a|o)", Reply: "*Retrasado* y *Retraso mental* son términos despectivos eliminados del vocabulario psiquiátrico y, según la OMS, la forma correcta para referiste a ese grupo de enfermedades y transtornos es *Trastorno del desarrollo intelectual* "},
-	{Filter: "retraso mental", Reply: "*Retrasado* y *Retraso mental* son términos despectivos eliminados del vocabulario psiquiátrico y, según la OMS, la forma correcta para referiste a ese grupo de enfermedades y transtornos es *Trastorno del desarrollo intelectual* "},
+	{Filter: "discapacitad(a|o)", Language: LanguageSpanish, Alternative: "persona con discapacidad", Reply: "Ante todo somos personas, y no queremos que se nos etiquete, puesto que la discapacidad es una característica más de todas las que se tiene, no lo único por lo que se debe reconocer.\nPor eso es importante anteponer la palabra *persona* y lo más aconsejable es utilizar el término *persona con discapacidad* y no *discapacitado*.\nMás info en https://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf"},
+	{Filter: "discapacitad(a|o) fisic(a|o)", Language: LanguageSpanish, Alternative: "persona con discapacidad física", Reply: "Ante todo somos personas, y no queremos que se nos etiquete, puesto que la discapacidad es una característica más de todas las que se tiene, no lo único por lo que se debe reconocer.\nPor eso es importante anteponer la palabra *persona* y lo más aconsejable es utilizar el término *persona con discapacidad* y no *discapacitada física*.\nMás info en https://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf"},
+	{Filter: "minusvalid(a|o)", Language: LanguageSpanish, Alternative: "persona con discapacidad", Reply: "*Minusválido* es un término peyorativo y vulnera la dignidad de las personas con discapacidad, al atribuirse un nulo o reducido valor a una persona, o utilizarse generalmente con elevada carga negativa. Considera usar *persona con discapacidad*.\nMás info en Más info en https://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf"},
+	{Filter: "diversidad funcional", Language: LanguageSpanish, Alternative: "discapacidad", Reply: "COCEMFE considera que el término *diversidad funcional* es un eufemismo, cargado de condescendencia que genera confusión, inseguridad jurídica y rebaja la protección que todavía es necesaria. El término *discapacidad* es el que aglutina derechos reconocidos legalmente y que cuenta con el mayor respaldo social. Considera usarlo.\nMás info en Más info en https://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf"},
+	{Filter: "retrasad(a|o)", Language: LanguageSpanish, Reply: "*Retrasado* y *Retraso mental* son términos despectivos eliminados del vocabulario psiquiátrico y, según la OMS, la forma correcta para referiste a ese grupo de enfermedades y transtornos es *Trastorno del desarrollo intelectual* "},
+	{Filter: "retraso mental", Language: LanguageSpanish, Alternative: "trastorno del desarrollo intelectual", Reply: "*Retrasado* y *Retraso mental* son términos despectivos eliminados del vocabulario psiquiátrico y, según la OMS, la forma correcta para referiste a ese grupo de enfermedades y transtornos es *Trastorno del desarrollo intelectual* "},
 
 	// Our own list
-	{Filter: "gentlem(a|e)n", Reply: "Instead of *gentlem(a|e)n*, perhaps you mean *folks*?... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "lad(y|ies)", Reply: "Instead of *lad(y|ies)*, perhaps you mean *folks*?... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "los chicos de", Reply: "En vez de *los chicos de*, quizá quisiste decir *el equipo de*, *los integrantes de*? Para más información (en inglés), puedes consultar https://www.dictionary.com/e/you-guys/... *[Considera editar tu mensaje para que sea más inclusivo]*"},
-	{Filter: "chicos", Reply: "En vez de *chicos*, quizá quisiste decir *chiques*, *colegas*, *grupo*, *personas*? Para más información (en inglés), puedes consultar https://www.dictionary.com/e/you-guys/... *[Considera editar tu mensaje para que sea más inclusivo]*"},
-	{Filter: "lgtb", Reply: "Desde hace un tiempo, el colectivo *LGTB+* recomienda añadir el carácter `+` a la palabra *LGTB*, pues existen orientaciones e identidades que, a pesar de no ser tan predominantes, representan a muchas personas. *[Considera editar tu mensaje para que sea más inclusivo]*"},
-	{Filter: "locura", Reply: "La palabra *locura* es considerada por algunas personas como irrespetuosa hacia las personas que sufren alguna enfermedad mental.\nQuizá quisiste decir *indignante*, *impensable*, *absurdo*, *incomprensible*? Has considerado usar un adjetivo diferente como *ridículo*?"},
-	{Filter: "locuron", Reply: "La palabra *locurón* o *locura* es considerada por algunas personas como irrespetuosa hacia las personas que sufren alguna enfermedad mental.\nQuizá quisiste decir *indignante*, *impensable*, *absurdo*, *incomprensible*? Has considerado usar un adjetivo diferente como *ridículo*?"},
-	{Filter: "loc(a|o)", Reply: "La palabra *loco/loca* es considerada por algunas personas como irrespetuosa hacia las personas que sufren alguna enfermedad mental.\nQuizá quisiste decir *indignante*, *impensable*, *absurdo*, *incomprensible*? Has considerado usar un adjetivo diferente como *ridículo*?"},
-	{Filter: "cakewalk", Reply: "Instead of *cakewalk*, perhaps you mean *easy*?... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "grandfathered in", Reply: "Instead of *grandfathered in*, perhaps you mean *exempting*? You can read more information in https://www.selfdefined.app/definitions/grandfathering/ ... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "grandfathering", Reply: "Instead of *grandfathering*, perhaps you mean *exempting*? You can read more information in https://www.selfdefined.app/definitions/grandfathering/ ... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "whitelist", Reply: "Instead of *whitelist*, perhaps you mean *allowlist*? You can read more information in https://www.linkedin.com/pulse/allowlist-blocklist-better-terms-everyone-lets-use-them-rob-black/ ... *[Please consider editing your message so it's more inclusive]*"},
-	{Filter: "blacklist", Reply: "Instead of *blacklist*, perhaps you mean *blocklist*? You can read more information in https://www.linkedin.com/pulse/allowlist-blocklist-better-terms-everyone-lets-use-them-rob-black/ ... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "gentlem(a|e)n", Language: LanguageEnglish, Alternative: "folks", Reply: "Instead of *gentlem(a|e)n*, perhaps you mean *folks*?... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "lad(y|ies)", Language: LanguageEnglish, Alternative: "folks", Reply: "Instead of *lad(y|ies)*, perhaps you mean *folks*?... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "los chicos de", Language: LanguageSpanish, Alternative: "el equipo de", Reply: "En vez de *los chicos de*, quizá quisiste decir *el equipo de*, *los integrantes de*? Para más información (en inglés), puedes consultar https://www.dictionary.com/e/you-guys/... *[Considera editar tu mensaje para que sea más inclusivo]*"},
+	{Filter: "chicos", Language: LanguageSpanish, Alternative: "colegas", Reply: "En vez de *chicos*, quizá quisiste decir *chiques*, *colegas*, *grupo*, *personas*? Para más información (en inglés), puedes consultar https://www.dictionary.com/e/you-guys/... *[Considera editar tu mensaje para que sea más inclusivo]*"},
+	{Filter: "lgtb", Language: LanguageSpanish, Alternative: "LGTB+", Reply: "Desde hace un tiempo, el colectivo *LGTB+* recomienda añadir el carácter `+` a la palabra *LGTB*, pues existen orientaciones e identidades que, a pesar de no ser tan predominantes, representan a muchas personas. *[Considera editar tu mensaje para que sea más inclusivo]*"},
+	{Filter: "locura", Language: LanguageSpanish, Reply: "La palabra *locura* es considerada por algunas personas como irrespetuosa hacia las personas que sufren alguna enfermedad mental.\nQuizá quisiste decir *indignante*, *impensable*, *absurdo*, *incomprensible*? Has considerado usar un adjetivo diferente como *ridículo*?"},
+	{Filter: "locuron", Language: LanguageSpanish, Reply: "La palabra *locurón* o *locura* es considerada por algunas personas como irrespetuosa hacia las personas que sufren alguna enfermedad mental.\nQuizá quisiste decir *indignante*, *impensable*, *absurdo*, *incomprensible*? Has considerado usar un adjetivo diferente como *ridículo*?"},
+	{Filter: "loc(a|o)", Language: LanguageSpanish, Reply: "La palabra *loco/loca* es considerada por algunas personas como irrespetuosa hacia las personas que sufren alguna enfermedad mental.\nQuizá quisiste decir *indignante*, *impensable*, *absurdo*, *incomprensible*? Has considerado usar un adjetivo diferente como *ridículo*?"},
+	{Filter: "cakewalk", Language: LanguageEnglish, Alternative: "easy", Reply: "Instead of *cakewalk*, perhaps you mean *easy*?... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "grandfathered in", Language: LanguageEnglish, Alternative: "exempted", Reply: "Instead of *grandfathered in*, perhaps you mean *exempting*? You can read more information in https://www.selfdefined.app/definitions/grandfathering/ ... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "grandfathering", Language: LanguageEnglish, Alternative: "exempting", Reply: "Instead of *grandfathering*, perhaps you mean *exempting*? You can read more information in https://www.selfdefined.app/definitions/grandfathering/ ... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "whitelist", Language: LanguageEnglish, Alternative: "allowlist", Reply: "Instead of *whitelist*, perhaps you mean *allowlist*? You can read more information in https://www.linkedin.com/pulse/allowlist-blocklist-better-terms-everyone-lets-use-them-rob-black/ ... *[Please consider editing your message so it's more inclusive]*"},
+	{Filter: "blacklist", Language: LanguageEnglish, Alternative: "blocklist", Reply: "Instead of *blacklist*, perhaps you mean *blocklist*? You can read more information in https://www.linkedin.com/pulse/allowlist-blocklist-better-terms-everyone-lets-use-them-rob-black/ ... *[Please consider editing your message so it's more inclusive]*"},
 
 	// Català
-	{Filter: "discapacita(t|da)", Reply: "Abans de res som persones, i no volem que se'ns etiqueti, ja que la discapacitat és una característica més de totes les que es té, no l'únic pel que s'ha de reconèixer.\nPer això és important anteposar la paraula *persona* i el més aconsellable és utilitzar el terme *persona amb discapacitat* i no *discapacitat*.\nMés info a https://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf"},
-	{Filter: "diversitat funcional", Reply: "La COCEMFE considera que el terme *diversitat funcional* és un eufemisme, carregat de condescendència que genera confusió, inseguretat jurídica i rebaixa la protecció que encara és necessària. El terme *discapacitat* és el que aglutina drets reconeguts legalment i que compta amb el major suport social. Considereu utilitzar-lo.\nMés info ahttps://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf"},
-	{Filter: "retrass*a(t|da)", Reply: "*Retrassat/retrassada* i *Retràs mental* són termes despectius eliminats del vocabulari psiquiàtric i, segons l'OMS, la forma correcta per referir-vos a aquest grup de malalties i trastorns és *Trastorn del desenvolupament intel·lectual*"},
-	{Filter: "retras mental", Reply: "*Retrassat/retrassada* i *Retràs mental* són termes despectius eliminats del vocabulari psiquiàtric i, segons l'OMS, la forma correcta per referir-vos a aquest grup de malalties i trastorns és *Trastorn del desenvolupament intel·lectual*"},
-	{Filter: "retarda(t|da)", Reply: "*Retardat/retardada* i *Retard mental* són termes despectius eliminats del vocabulari psiquiàtric i, segons l'OMS, la forma correcta per referir-vos a aquest grup de malalties i trastorns és *Trastorn del desenvolupament intel·lectual*"},
-	{Filter: "retard mental", Reply: "*Retardat/retardada* i *Retard mental* són termes despectius eliminats del vocabulari psiquiàtric i, segons l'OMS, la forma correcta per referir-vos a aquest grup de malalties i trastorns és *Trastorn del desenvolupament intel·lectual*"},
-	{Filter: "noi(a|es|s)*", Reply: "En lloc de *noi(a|es|s)*, potser et refereixes a *gent*?... *[Si us plau, considera editar el teu missatge perquè sigui més inclusiu]*"},
-	{Filter: "bo(g|j)eria", Reply: "La paraula *bogeria* és considerada per algunes persones com a irrespectuosa cap a les persones que pateixen alguna malaltia mental.\nPotser has volgut dir *indignant*, *impensable*, *absurd* o *incomprensible*? Has considerat fer servir un adjectiu diferent com *ridícul*?"},
-	{Filter: "bo(ig|ja|jos)", Reply: "La paraula *boig/boja* és considerada per algunes persones com a irrespectuosa cap a les persones que pateixen alguna malaltia mental.\nPotser has volgut dir *indignant*, *impensable*, *absurd* o *incomprensible*? Has considerat fer servir un adjectiu diferent com *ridícul*?"},
+	{Filter: "discapacita(t|da)", Language: LanguageCatalan, Alternative: "persona amb discapacitat", Reply: "Abans de res som persones, i no volem que se'ns etiqueti, ja que la discapacitat és una característica més de totes les que es té, no l'únic pel que s'ha de reconèixer.\nPer això és important anteposar la paraula *persona* i el més aconsellable és utilitzar el terme *persona amb discapacitat* i no *discapacitat*.\nMés info a https://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf"},
+	{Filter: "diversitat funcional", Language: LanguageCatalan, Alternative: "discapacitat", Reply: "La COCEMFE considera que el terme *diversitat funcional* és un eufemisme, carregat de condescendència que genera confusió, inseguretat jurídica i rebaixa la protecció que encara és necessària. El terme *discapacitat* és el que aglutina drets reconeguts legalment i que compta amb el major suport social. Considereu utilitzar-lo.\nMés info ahttps://www.cocemfe.es/wp-content/uploads/2019/02/20181010_COCEMFE_Lenguaje_inclusivo.pdf"},
+	{Filter: "retrass*a(t|da)", Language: LanguageCatalan, Reply: "*Retrassat/retrassada* i *Retràs mental* són termes despectius eliminats del vocabulari psiquiàtric i, segons l'OMS, la forma correcta per referir-vos a aquest grup de malalties i trastorns és *Trastorn del desenvolupament intel·lectual*"},
+	{Filter: "retras mental", Language: LanguageCatalan, Alternative: "trastorn del desenvolupament intel·lectual", Reply: "*Retrassat/retrassada* i *Retràs mental* són termes despectius eliminats del vocabulari psiquiàtric i, segons l'OMS, la forma correcta per referir-vos a aquest grup de malalties i trastorns és *Trastorn del desenvolupament intel·lectual*"},
+	{Filter: "retarda(t|da)", Language: LanguageCatalan, Reply: "*Retardat/retardada* i *Retard mental* són termes despectius eliminats del vocabulari psiquiàtric i, segons l'OMS, la forma correcta per referir-vos a aquest grup de malalties i trastorns és *Trastorn del desenvolupament intel·lectual*"},
+	{Filter: "retard mental", Language: LanguageCatalan, Alternative: "trastorn del desenvolupament intel·lectual", Reply: "*Retardat/retardada* i *Retard mental* són termes despectius eliminats del vocabulari psiquiàtric i, segons l'OMS, la forma correcta per referir-vos a aquest grup de malalties i trastorns és *Trastorn del desenvolupament intel·lectual*"},
+	{Filter: "noi(a|es|s)*", Language: LanguageCatalan, Reply: "En lloc de *noi(a|es|s)*, potser et refereixes a *gent*?... *[Si us plau, considera editar el teu missatge perquè sigui més inclusiu]*"},
+	{Filter: "bo(g|j)eria", Language: LanguageCatalan, Reply: "La paraula *bogeria* és considerada per algunes persones com a irrespectuosa cap a les persones que pateixen alguna malaltia mental.\nPotser has volgut dir *indignant*, *impensable*, *absurd* o *incomprensible*? Has considerat fer servir un adjectiu diferent com *ridícul*?"},
+	{Filter: "bo(ig|ja|jos)", Language: LanguageCatalan, Reply: "La paraula *boig/boja* és considerada per algunes persones com a irrespectuosa cap a les persones que pateixen alguna malaltia mental.\nPotser has volgut dir *indignant*, *impensable*, *absurd* o *incomprensible*? Has considerat fer servir un adjectiu diferent com *ridícul*?"},
 }
 
 type FilteredText struct {
@@ -79,27 +86,61 @@ type FilteredText struct {
 	Reply    string
 }
 
+// defaultRuleset holds the built-in filters, compiled once.
+var defaultRuleset = func() *Ruleset {
+	rs, err := NewRuleset()
+	if err != nil {
+		panic(fmt.Sprintf("invalid built-in inclusive filters: %s", err))
+	}
+
+	return rs
+}()
+
+// Filter returns the first filter matching the input, if any. Only filters of the languages the input is written in
+// are applied, or all of them if the language can't be detected. Extra filters replace the built-in ones with the
+// same pattern, and are appended after them otherwise.
+//
+// Extra filters are compiled on every call. If any of them is invalid, the error is logged and only the built-in
+// filters apply. Prefer building a Ruleset once with NewRuleset, which returns the error instead.
 func Filter(input string, extraFilters ...InclusiveFilter) *InclusiveFilter {
-	// Removing accents and others before matching
-	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
-	text, _, _ := transform.String(t, strings.ToLower(input))
-
-	filters := append(inclusiveFilters, extraFilters...)
-	for _, word := range filters {
-		if word.regex == nil {
-			// If it's just one word, ensure its bounded as it should.
-			if !strings.Contains(word.Filter, " ") {
-				word.Filter = fmt.Sprintf("(?:^|\\W)%s(?:$|[^\\w+])", word.Filter)
-			}
-
-			word.regex, _ = regexp.Compile(word.Filter)
+	return FilterIn(input, "", "", extraFilters...)
+}
+
+// FilterIn works like Filter, skipping the filters not enabled in the given channel. An empty channel enables all of them.
+// The language hint is the language assumed for the input when it can't be detected. Empty applies all the filters in that case.
+func FilterIn(input, channel, languageHint string, extraFilters ...InclusiveFilter) *InclusiveFilter {
+	rs := defaultRuleset
+	if len(extraFilters) > 0 {
+		var err error
+		if rs, err = NewRuleset(extraFilters...); err != nil {
+			log.Printf("[ERROR] Ignoring invalid inclusive language filters: %s", err)
+			rs = defaultRuleset
 		}
+	}
 
-		if word.regex.MatchString(text) {
-			word.Reply += conductLinks
-			return &word
+	return rs.First(input, channel, languageHint)
+}
+
+// appliesTo returns true if the filter applies to any of the given languages. Filters without language, or an
+// unknown language, apply always.
+func (f InclusiveFilter) appliesTo(languages []string) bool {
+	if f.Language == "" || len(languages) == 0 {
+		return true
+	}
+
+	for _, l := range languages {
+		if l == f.Language {
+			return true
 		}
 	}
 
-	return nil
+	return false
+}
+
+func conductLinksIn(language string) string {
+	if links, ok := conductLinks[language]; ok {
+		return links
+	}
+
+	return conductLinks[LanguageEnglish]
 }
@@ -46,3 +46,34 @@ func TestFilter(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterIn_Language(t *testing.T) {
+	tests := []struct {
+		name         string
+		input        string
+		languageHint string
+		filtered     bool
+		reply        string
+	}{
+		{name: "spanish rules are not applied to english text", input: "The loco variable is used in the parser", filtered: false},
+		{name: "catalan rules are not applied to english text", input: "Is there any noia library for this?", filtered: false},
+		{name: "spanish rules are applied to spanish text", input: "Ese tío está loco", filtered: true, reply: "Código de Conducta"},
+		{name: "english rules are applied to english text", input: "That idea is just crazy", filtered: true, reply: "Code of Conduct"},
+		{name: "all rules are applied if undetected", input: "loco", filtered: true},
+		{name: "the channel hint is used if undetected", input: "loco", languageHint: LanguageEnglish, filtered: false},
+		{name: "the channel hint is ignored if detected", input: "Ese tío está loco", languageHint: LanguageEnglish, filtered: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := FilterIn(test.input, "", test.languageHint)
+			if !test.filtered {
+				require.Nil(t, output, output)
+				return
+			}
+
+			require.NotNil(t, output)
+			require.Contains(t, output.Reply, test.reply)
+		})
+	}
+}
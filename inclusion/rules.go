@@ -0,0 +1,195 @@
+package inclusion
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"strings"
+	"unicode"
+
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+)
+
+// Languages of the filters.
+const (
+	LanguageEnglish = "en"
+	LanguageSpanish = "es"
+	LanguageCatalan = "ca"
+)
+
+// SetBuiltIn is the rule set of the built-in filters.
+const SetBuiltIn = "builtin"
+
+// Severities of the filters.
+const (
+	SeverityLow    = "low"
+	SeverityMedium = "medium"
+	SeverityHigh   = "high"
+)
+
+// rulesFile is the format of the files filters are loaded from. The language and set apply to the filters not setting any.
+type rulesFile struct {
+	Language string            `toml:"language" yaml:"language"`
+	Set      string            `toml:"set" yaml:"set"`
+	Rules    []InclusiveFilter `toml:"rules" yaml:"rules"`
+}
+
+// LoadRules reads filters from TOML (.toml) or YAML (.yaml, .yml) files with the following format:
+//
+//	language = "en"
+//	set = "tech" # The name of the file, without extension, if not set.
+//
+//	[[rules]]
+//	pattern = "sanity check"
+//	reply = "Instead of *sanity check*, perhaps you mean *quick check*?"
+//	severity = "low"
+//	channels = ["general"]
+//	exemptions = ["sanity check\\(\\)"]
+//
+// Loaded filters are validated, and meant to be passed as extra filters to Filter, so they are merged with the built-in ones.
+func LoadRules(paths ...string) ([]InclusiveFilter, error) {
+	var filters []InclusiveFilter
+	for _, p := range paths {
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return nil, err
+		}
+
+		var f rulesFile
+		switch strings.ToLower(path.Ext(p)) {
+		case ".toml":
+			err = toml.Unmarshal(data, &f)
+		case ".yaml", ".yml":
+			err = yaml.Unmarshal(data, &f)
+		default:
+			return nil, fmt.Errorf("%s inclusion rules file extension not supported", path.Ext(p))
+		}
+		if err != nil {
+			return nil, fmt.Errorf("decode inclusion rules file %q: %w", p, err)
+		}
+
+		if f.Set == "" {
+			f.Set = strings.TrimSuffix(path.Base(p), path.Ext(p))
+		}
+		for i := range f.Rules {
+			if f.Rules[i].Language == "" {
+				f.Rules[i].Language = f.Language
+			}
+			if f.Rules[i].Set == "" {
+				f.Rules[i].Set = f.Set
+			}
+		}
+
+		if err := ValidateRules(f.Rules...); err != nil {
+			return nil, fmt.Errorf("inclusion rules file %q: %w", p, err)
+		}
+		filters = append(filters, f.Rules...)
+	}
+
+	return filters, nil
+}
+
+// ValidateRules checks the filters are usable, and their patterns compile.
+func ValidateRules(filters ...InclusiveFilter) error {
+	for _, f := range filters {
+		if f.Filter == "" || f.Reply == "" {
+			return errors.New("rules should have both pattern and reply")
+		}
+		if _, err := compilePattern(f.Filter); err != nil {
+			return fmt.Errorf("rule %q: %w", f.Filter, err)
+		}
+		if !f.CaseSensitive && hasUppercase(f.Filter) {
+			return fmt.Errorf("rule %q should be lowercase, or case sensitive", f.Filter)
+		}
+		for _, e := range f.Exemptions {
+			if _, err := compilePattern(e); err != nil {
+				return fmt.Errorf("rule %q exemption %q: %w", f.Filter, e, err)
+			}
+			if !f.CaseSensitive && hasUppercase(e) {
+				return fmt.Errorf("rule %q exemption %q should be lowercase, or the rule case sensitive", f.Filter, e)
+			}
+		}
+
+		switch f.Language {
+		case "", LanguageEnglish, LanguageSpanish, LanguageCatalan:
+		default:
+			return fmt.Errorf("rule %q language should be one of %s, %s or %s, got %q", f.Filter, LanguageEnglish, LanguageSpanish, LanguageCatalan, f.Language)
+		}
+
+		switch f.Severity {
+		case "", SeverityLow, SeverityMedium, SeverityHigh:
+		default:
+			return fmt.Errorf("rule %q severity should be one of %s, %s or %s, got %q", f.Filter, SeverityLow, SeverityMedium, SeverityHigh, f.Severity)
+		}
+	}
+
+	return nil
+}
+
+// hasUppercase returns true if the pattern matches uppercase letters, which never happens in lowercased texts.
+// Escapes like \W or \p{Lu} and group names are not matched against the text, so they are ignored.
+func hasUppercase(pattern string) bool {
+	runes := []rune(pattern)
+	for i := 0; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\' && i+1 < len(runes):
+			i++
+			if (runes[i] == 'p' || runes[i] == 'P') && i+1 < len(runes) && runes[i+1] == '{' {
+				for i < len(runes) && runes[i] != '}' {
+					i++
+				}
+			}
+		case strings.HasPrefix(string(runes[i:]), "(?P<"):
+			for i < len(runes) && runes[i] != '>' {
+				i++
+			}
+		case unicode.IsUpper(runes[i]):
+			return true
+		}
+	}
+
+	return false
+}
+
+// EnabledIn returns true if the filter is enabled in the given channel.
+func (f InclusiveFilter) EnabledIn(channel string) bool {
+	if len(f.Channels) == 0 {
+		return true
+	}
+
+	for _, c := range f.Channels {
+		if c == channel {
+			return true
+		}
+	}
+
+	return false
+}
+
+// mergeFilters returns the default filters, replacing the ones with the same pattern as any of the extra filters.
+// The rest of extra filters are appended.
+func mergeFilters(defaults, extra []InclusiveFilter) []InclusiveFilter {
+	if len(extra) == 0 {
+		return defaults
+	}
+
+	merged := make([]InclusiveFilter, len(defaults), len(defaults)+len(extra))
+	copy(merged, defaults)
+
+	index := make(map[string]int, len(defaults))
+	for i, f := range defaults {
+		index[strings.ToLower(f.Filter)] = i
+	}
+
+	for _, f := range extra {
+		if i, ok := index[strings.ToLower(f.Filter)]; ok {
+			merged[i] = f
+			continue
+		}
+		merged = append(merged, f)
+	}
+
+	return merged
+}
@@ -0,0 +1,105 @@
+package inclusion
+
+import (
+	"bytes"
+	"log"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte(content), 0o600))
+		return p
+	}
+
+	tomlRules := write("en.toml", `
+language = "en"
+
+[[rules]]
+pattern = "sanity check"
+reply = "Instead of *sanity check*, perhaps you mean *quick check*?"
+severity = "low"
+channels = ["CGENERAL"]
+`)
+	yamlRules := write("es.yaml", `
+language: es
+set: strict
+rules:
+  - pattern: chicos
+    reply: Prueba con *gente*.
+    exemptions: ["chicos malos"]
+  - pattern: cracks
+    reply: Prueba con *equipo*.
+    language: ca
+`)
+
+	rules, err := LoadRules(tomlRules, yamlRules)
+	require.NoError(t, err)
+	require.Len(t, rules, 3)
+	require.Equal(t, LanguageEnglish, rules[0].Language)
+	require.Equal(t, []string{"CGENERAL"}, rules[0].Channels)
+	require.Equal(t, LanguageSpanish, rules[1].Language, "the file language applies to rules not setting any")
+	require.Equal(t, LanguageCatalan, rules[2].Language)
+	require.Equal(t, "en", rules[0].Set, "the file name is the set if the file doesn't set any")
+	require.Equal(t, "strict", rules[1].Set)
+
+	tests := []struct {
+		name    string
+		file    string
+		content string
+	}{
+		{name: "invalid pattern", file: "invalid.toml", content: "[[rules]]\npattern = \"guys(\"\nreply = \"...\""},
+		{name: "missing reply", file: "no_reply.yaml", content: "rules:\n  - pattern: guys"},
+		{name: "unknown language", file: "language.yml", content: "language: fr\nrules:\n  - pattern: mecs\n    reply: ..."},
+		{name: "unknown severity", file: "severity.toml", content: "[[rules]]\npattern = \"guys\"\nreply = \"...\"\nseverity = \"critical\""},
+		{name: "uppercase pattern", file: "uppercase.toml", content: "[[rules]]\npattern = \"Guys\"\nreply = \"...\""},
+		{name: "uppercase exemption", file: "uppercase.yaml", content: "rules:\n  - pattern: guys\n    reply: ...\n    exemptions: [\"Guys and Dolls\"]"},
+		{name: "unsupported extension", file: "rules.json", content: "{}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadRules(write(tt.file, tt.content))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestValidateRules_Case(t *testing.T) {
+	require.NoError(t, ValidateRules(InclusiveFilter{Filter: "URG", Reply: "...", CaseSensitive: true}))
+	require.NoError(t, ValidateRules(InclusiveFilter{Filter: `guys\W\p{Lu}(?P<Name>x)`, Reply: "..."}), "escapes and group names are not matched")
+	require.Error(t, ValidateRules(InclusiveFilter{Filter: "URG", Reply: "..."}))
+	require.Error(t, ValidateRules(InclusiveFilter{Filter: "guys", Reply: "...", Exemptions: []string{"Guys and Dolls"}}))
+}
+
+func TestValidateRules_BuiltIn(t *testing.T) {
+	require.NoError(t, ValidateRules(inclusiveFilters...))
+}
+
+func TestFilterIn(t *testing.T) {
+	rules := []InclusiveFilter{
+		{Filter: "chicos", Reply: "Custom reply", Exemptions: []string{"chicos malos"}},
+		{Filter: "sanity check", Reply: "Perhaps you mean *quick check*?", Channels: []string{"CGENERAL"}},
+	}
+
+	filtered := FilterIn("hola chicos", "CRANDOM", "", rules...)
+	require.NotNil(t, filtered)
+	require.Contains(t, filtered.Reply, "Custom reply", "extra rules replace the built-in ones with the same pattern")
+
+	require.Nil(t, FilterIn("los chicos malos", "CRANDOM", "", rules...), "exempted")
+
+	require.NotNil(t, FilterIn("just a sanity check", "CGENERAL", "", rules...))
+	require.Nil(t, FilterIn("just a sanity check", "CRANDOM", "", rules...), "not enabled in the channel")
+	require.NotNil(t, Filter("just a sanity check", rules...), "all rules apply without channel")
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	defer log.SetOutput(os.Stderr)
+	require.NotNil(t, FilterIn("hey guys", "", "", InclusiveFilter{Filter: "broken(", Reply: "..."}), "built-in rules still apply")
+	require.Contains(t, logs.String(), `"broken("`, "the invalid pattern must be reported")
+}
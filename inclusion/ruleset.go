@@ -0,0 +1,245 @@
+package inclusion
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// Match is an occurrence of a filter in a text.
+type Match struct {
+	Filter InclusiveFilter
+	Start  int    // Byte offset in the input where the matched text starts.
+	End    int    // Byte offset in the input where the matched text ends.
+	Text   string // The matched text, as written in the input.
+	order  int    // Position of the filter in the ruleset. Lower is more specific.
+}
+
+// Ruleset is a compiled set of filters, ready to be matched against texts. It is safe for concurrent use.
+// A nil Ruleset holds the built-in filters only.
+type Ruleset struct {
+	rules []rule
+}
+
+type rule struct {
+	filter     InclusiveFilter
+	regex      *regexp.Regexp
+	exemptions []*regexp.Regexp
+}
+
+// NewRuleset compiles the built-in filters along with the extra ones. Extra filters replace the built-in ones with the
+// same pattern, and are appended after them otherwise. Returns an error if any filter is invalid.
+func NewRuleset(extraFilters ...InclusiveFilter) (*Ruleset, error) {
+	if err := ValidateRules(extraFilters...); err != nil {
+		return nil, err
+	}
+
+	builtIn := make([]InclusiveFilter, len(inclusiveFilters))
+	for i, f := range inclusiveFilters {
+		f.Set = SetBuiltIn
+		builtIn[i] = f
+	}
+
+	filters := mergeFilters(builtIn, extraFilters)
+	rs := &Ruleset{rules: make([]rule, 0, len(filters))}
+	for _, f := range filters {
+		regex, err := compilePattern(f.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", f.Filter, err)
+		}
+
+		r := rule{filter: f, regex: regex}
+		for _, e := range f.Exemptions {
+			exemption, err := compilePattern(e)
+			if err != nil {
+				return nil, fmt.Errorf("rule %q exemption %q: %w", f.Filter, e, err)
+			}
+			r.exemptions = append(r.exemptions, exemption)
+		}
+		rs.rules = append(rs.rules, r)
+	}
+
+	return rs, nil
+}
+
+// Only returns a ruleset with just the filters of the given rule sets, keeping their order. Returns an error if any
+// of the sets has no filters.
+func (rs *Ruleset) Only(sets ...string) (*Ruleset, error) {
+	if rs == nil {
+		rs = defaultRuleset
+	}
+
+	wanted := make(map[string]bool, len(sets))
+	for _, s := range sets {
+		wanted[s] = false
+	}
+
+	only := &Ruleset{}
+	for _, r := range rs.rules {
+		if _, ok := wanted[r.filter.Set]; ok {
+			wanted[r.filter.Set] = true
+			only.rules = append(only.rules, r)
+		}
+	}
+
+	for _, s := range sets {
+		if !wanted[s] {
+			return nil, fmt.Errorf("rule set %q has no rules", s)
+		}
+	}
+
+	return only, nil
+}
+
+// Match returns every occurrence of the filters in the text, sorted by position. Only filters of the languages
+// the text is written in are applied, or all of them if the language can't be detected.
+func (rs *Ruleset) Match(text string) []Match {
+	return rs.MatchIn(text, "", "")
+}
+
+// MatchIn works like Match, skipping the filters not enabled in the given channel. An empty channel enables all of them.
+// The language hint is the language assumed for the text when it can't be detected. Empty applies all the filters in that case.
+func (rs *Ruleset) MatchIn(text, channel, languageHint string) []Match {
+	if rs == nil {
+		rs = defaultRuleset
+	}
+
+	languages := DetectLanguages(text)
+	if languages == nil && languageHint != "" {
+		languages = []string{languageHint}
+	}
+
+	normalized, offsets := normalize(text, false)
+	var cased string // The text keeping its case, for case sensitive filters. Only normalized if needed.
+	var casedOffsets []int
+
+	var matches []Match
+	for i, r := range rs.rules {
+		if channel != "" && !r.filter.EnabledIn(channel) {
+			continue
+		}
+		if !r.filter.appliesTo(languages) || r.exempted(normalized) {
+			continue
+		}
+
+		input, inputOffsets := normalized, offsets
+		if r.filter.CaseSensitive {
+			if casedOffsets == nil {
+				cased, casedOffsets = normalize(text, true)
+			}
+			input, inputOffsets = cased, casedOffsets
+		}
+
+		for from := 0; from < len(input); {
+			loc := r.regex.FindStringSubmatchIndex(input[from:])
+			if loc == nil {
+				break
+			}
+
+			// The first group is the term itself, without the surrounding word boundaries.
+			start, end := from+loc[2], from+loc[3]
+			matches = append(matches, Match{
+				Filter: r.filter,
+				Start:  inputOffsets[start],
+				End:    inputOffsets[end],
+				Text:   text[inputOffsets[start]:inputOffsets[end]],
+				order:  i,
+			})
+
+			// The next match could start right at the boundary after this one.
+			from = end
+			if end == start {
+				from++
+			}
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		return matches[i].Start < matches[j].Start
+	})
+
+	return matches
+}
+
+// First works like MatchIn, but only returns the filter matched first in the ruleset, with the links to the
+// Code of Conduct appended to its reply. Returns nil if there is no match.
+func (rs *Ruleset) First(text, channel, languageHint string) *InclusiveFilter {
+	matches := rs.MatchIn(text, channel, languageHint)
+	if len(matches) == 0 {
+		return nil
+	}
+
+	// The first filter of the ruleset wins, as they go from the most to the least specific.
+	first := matches[0]
+	for _, m := range matches[1:] {
+		if m.order < first.order {
+			first = m
+		}
+	}
+
+	filter := first.Filter
+	filter.Reply += conductLinksIn(filter.Language)
+	return &filter
+}
+
+func (r rule) exempted(normalized string) bool {
+	for _, e := range r.exemptions {
+		if e.MatchString(normalized) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compilePattern compiles a filter pattern, capturing the term in the first group. If it's just one word, ensure its
+// bounded as it should. Patterns are matched against lowercased texts, unless they are case sensitive.
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if !strings.Contains(pattern, " ") {
+		return regexp.Compile(fmt.Sprintf("(?:^|\\W)(%s)(?:$|[^\\w+])", pattern))
+	}
+
+	return regexp.Compile(fmt.Sprintf("(%s)", pattern))
+}
+
+// accentsRemover removes accents and others before matching.
+var accentsRemover = transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+
+// normalize lowercases the text, unless the case has to be kept, and removes its accents. It also returns the offset
+// in the text of every byte of the normalized text, plus the length of the text, so matches can be mapped back to the
+// original text.
+func normalize(text string, keepCase bool) (string, []int) {
+	var b strings.Builder
+	b.Grow(len(text))
+	offsets := make([]int, 0, len(text)+1)
+
+	for i, r := range text {
+		if r < utf8.RuneSelf {
+			if !keepCase {
+				r = unicode.ToLower(r)
+			}
+			b.WriteByte(byte(r))
+			offsets = append(offsets, i)
+			continue
+		}
+
+		char := string(r)
+		if !keepCase {
+			char = strings.ToLower(char)
+		}
+		normalized, _, _ := transform.String(accentsRemover, char)
+		b.WriteString(normalized)
+		for j := 0; j < len(normalized); j++ {
+			offsets = append(offsets, i)
+		}
+	}
+
+	return b.String(), append(offsets, len(text))
+}
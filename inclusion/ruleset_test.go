@@ -0,0 +1,120 @@
+package inclusion
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/transform"
+)
+
+func TestNewRuleset_Invalid(t *testing.T) {
+	_, err := NewRuleset(InclusiveFilter{Filter: "guys(", Reply: "Invalid"})
+	require.Error(t, err)
+
+	_, err = NewRuleset(InclusiveFilter{Filter: "guys", Reply: "Invalid exemption", Exemptions: []string{"you guys("}})
+	require.Error(t, err)
+}
+
+func TestRulesetMatch(t *testing.T) {
+	rs, err := NewRuleset(InclusiveFilter{
+		Filter:     "crazy",
+		Reply:      "Try wild instead.",
+		Language:   LanguageEnglish,
+		Exemptions: []string{"crazy ivan"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		input string
+		texts []string
+		start []int
+	}{
+		{name: "no match", input: "Hello everyone", texts: nil},
+		{name: "all matches are returned sorted", input: "Thanks guys, that's CRAZY", texts: []string{"guys", "CRAZY"}, start: []int{7, 20}},
+		{name: "repeated adjacent terms", input: "guys guys guys", texts: []string{"guys", "guys", "guys"}, start: []int{0, 5, 10}},
+		{name: "spans keep the original accents", input: "Está locá", texts: []string{"locá"}, start: []int{6}},
+		{name: "exempted rule", input: "The crazy ivan manoeuvre", texts: nil},
+		{name: "case sensitive acronyms", input: "Our café program is for URM students, urm, I think", texts: []string{"URM"}, start: []int{25}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matches := rs.Match(test.input)
+			require.Len(t, matches, len(test.texts))
+			for i, m := range matches {
+				require.Equal(t, test.texts[i], m.Text)
+				require.Equal(t, test.start[i], m.Start)
+				require.Equal(t, m.Text, test.input[m.Start:m.End])
+			}
+		})
+	}
+}
+
+func TestRulesetOnly(t *testing.T) {
+	rs, err := NewRuleset(InclusiveFilter{Filter: "sanity check", Reply: "Try quick check.", Set: "tech"})
+	require.NoError(t, err)
+
+	tech, err := rs.Only("tech")
+	require.NoError(t, err)
+	require.Len(t, tech.Match("hi guys, just a sanity check"), 1)
+
+	builtIn, err := rs.Only(SetBuiltIn)
+	require.NoError(t, err)
+	require.Len(t, builtIn.Match("hi guys, just a sanity check"), 2, "hi guys and guys")
+
+	_, err = rs.Only("tech", "unknown")
+	require.Error(t, err)
+}
+
+func TestRulesetMatch_Nil(t *testing.T) {
+	var rs *Ruleset
+	require.NotEmpty(t, rs.Match("hi guys"), "a nil ruleset applies the built-in filters")
+}
+
+var benchmarkInputs = []string{
+	"Hey guys, the deploy is done",
+	"Mi vecina es una persona muy maja, buena localización",
+	"Nothing to see here, just a regular message about Go generics and how nice they are",
+}
+
+func BenchmarkRulesetMatch(b *testing.B) {
+	rs, err := NewRuleset()
+	require.NoError(b, err)
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for _, input := range benchmarkInputs {
+			_ = rs.First(input, "", "")
+		}
+	}
+}
+
+func BenchmarkLegacyFilter(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		for _, input := range benchmarkInputs {
+			_ = legacyFilter(input)
+		}
+	}
+}
+
+// legacyFilter is how Filter used to work before rules were compiled into a Ruleset, recompiling every
+// pattern on each call. Kept to benchmark against.
+func legacyFilter(input string) *InclusiveFilter {
+	text, _, _ := transform.String(accentsRemover, strings.ToLower(input))
+	for _, word := range inclusiveFilters {
+		pattern := word.Filter
+		if !strings.Contains(pattern, " ") {
+			pattern = fmt.Sprintf("(?:^|\\W)%s(?:$|[^\\w+])", pattern)
+		}
+
+		if regexp.MustCompile(pattern).MatchString(text) {
+			return &word
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package inclusion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// suggestionTexts are the texts of replies listing several matches, in the language of the first match.
+var suggestionTexts = map[string]struct{ header, rewrite string }{
+	LanguageEnglish: {header: "Some words in your message may not be inclusive:", rewrite: "Suggested rewrite:"},
+	LanguageSpanish: {header: "Algunas palabras de tu mensaje podrían no ser inclusivas:", rewrite: "Propuesta de mensaje:"},
+	LanguageCatalan: {header: "Algunes paraules del teu missatge podrien no ser inclusives:", rewrite: "Proposta de missatge:"},
+}
+
+// Suggestion gathers the matches of a text, so they can be replied at once.
+type Suggestion struct {
+	Matches []Match // Non-overlapping matches, sorted by position.
+	Rewrite string  // The text with the alternatives of the matches applied. Empty if no match has an alternative.
+}
+
+// Suggest returns the matches of the text, like MatchIn does, dropping the ones overlapping an earlier filter
+// of the ruleset, which is more specific. Returns nil if there is no match.
+func (rs *Ruleset) Suggest(text, channel, languageHint string) *Suggestion {
+	matches := dedupeMatches(rs.MatchIn(text, channel, languageHint))
+	if len(matches) == 0 {
+		return nil
+	}
+
+	return &Suggestion{Matches: matches, Rewrite: Rewrite(text, matches)}
+}
+
+// Reply returns the reply to send to the author of the text. The reply of the filter is used as is if there is just
+// one, and every matched term is listed with its alternative otherwise.
+func (s Suggestion) Reply() string {
+	language := s.Matches[0].Filter.Language
+	texts, ok := suggestionTexts[language]
+	if !ok {
+		texts = suggestionTexts[LanguageEnglish]
+	}
+
+	var b strings.Builder
+	if len(s.Matches) == 1 {
+		b.WriteString(s.Matches[0].Filter.Reply)
+	} else {
+		b.WriteString(texts.header)
+		listed := make(map[string]bool, len(s.Matches))
+		for _, m := range s.Matches {
+			term := strings.ToLower(m.Text)
+			if listed[term] {
+				continue
+			}
+			listed[term] = true
+
+			if m.Filter.Alternative != "" {
+				fmt.Fprintf(&b, "\n• *%s* → *%s*", m.Text, matchCase(m.Text, m.Filter.Alternative))
+			} else {
+				fmt.Fprintf(&b, "\n• *%s*: %s", m.Text, m.Filter.Reply)
+			}
+		}
+	}
+
+	if s.Rewrite != "" {
+		fmt.Fprintf(&b, "\n%s\n>%s", texts.rewrite, strings.ReplaceAll(s.Rewrite, "\n", "\n>"))
+	}
+
+	return b.String() + conductLinksIn(language)
+}
+
+// Filters returns the patterns of the matched filters, without duplicates.
+func (s Suggestion) Filters() []string {
+	var filters []string
+	seen := make(map[string]bool, len(s.Matches))
+	for _, m := range s.Matches {
+		if !seen[m.Filter.Filter] {
+			seen[m.Filter.Filter] = true
+			filters = append(filters, m.Filter.Filter)
+		}
+	}
+
+	return filters
+}
+
+// dedupeMatches drops the matches overlapping another one of an earlier filter in the ruleset. The result is sorted by position.
+func dedupeMatches(matches []Match) []Match {
+	byOrder := make([]Match, len(matches))
+	copy(byOrder, matches)
+	sort.SliceStable(byOrder, func(i, j int) bool {
+		return byOrder[i].order < byOrder[j].order
+	})
+
+	var kept []Match
+	for _, m := range byOrder {
+		overlaps := false
+		for _, k := range kept {
+			if m.Start < k.End && k.Start < m.End {
+				overlaps = true
+				break
+			}
+		}
+		if !overlaps {
+			kept = append(kept, m)
+		}
+	}
+
+	sort.SliceStable(kept, func(i, j int) bool {
+		return kept[i].Start < kept[j].Start
+	})
+
+	return kept
+}
+
+// Rewrite replaces every match having an alternative in the text. The matches must have been found in a text with
+// the same byte offsets, like the original text of a masked one. Returns empty if there is nothing to replace.
+func Rewrite(text string, matches []Match) string {
+	var b strings.Builder
+	var last int
+	var replaced bool
+	for _, m := range matches {
+		if m.Filter.Alternative == "" {
+			continue
+		}
+
+		b.WriteString(text[last:m.Start])
+		b.WriteString(matchCase(m.Text, m.Filter.Alternative))
+		last = m.End
+		replaced = true
+	}
+	if !replaced {
+		return ""
+	}
+
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// matchCase adapts the case of the alternative to the one of the term it replaces: all caps or capitalized.
+func matchCase(term, alternative string) string {
+	if utf8.RuneCountInString(term) > 1 && strings.ToUpper(term) == term && strings.ToLower(term) != term {
+		return strings.ToUpper(alternative)
+	}
+
+	first, _ := utf8.DecodeRuneInString(term)
+	if !unicode.IsUpper(first) {
+		return alternative
+	}
+
+	r, size := utf8.DecodeRuneInString(alternative)
+	return string(unicode.ToUpper(r)) + alternative[size:]
+}
@@ -0,0 +1,73 @@
+package inclusion
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		language string
+		terms    []string
+		rewrite  string
+	}{
+		{name: "no match", input: "Hello everyone", terms: nil},
+		{name: "every match is returned", input: "hey guys, that's crazy", terms: []string{"hey guys", "crazy"}, rewrite: "hey y'all, that's ridiculous"},
+		{name: "the more specific rule wins on overlaps", input: "Thanks to the guys of the whitelist team", terms: []string{"the guys", "whitelist"}, rewrite: "Thanks to the folks of the allowlist team"},
+		{name: "case is kept in the rewrite", input: "Guys, update the BLACKLIST", terms: []string{"Guys", "BLACKLIST"}, rewrite: "Folks, update the BLOCKLIST"},
+		{name: "no rewrite without alternatives", input: "És un noi simpàtic", language: LanguageCatalan, terms: []string{"noi"}, rewrite: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			language := test.language
+			if language == "" {
+				language = LanguageEnglish
+			}
+
+			suggestion := defaultRuleset.Suggest(test.input, "", language)
+			if test.terms == nil {
+				require.Nil(t, suggestion)
+				return
+			}
+
+			require.NotNil(t, suggestion)
+			var terms []string
+			for _, m := range suggestion.Matches {
+				terms = append(terms, m.Text)
+			}
+			require.Equal(t, test.terms, terms)
+			require.Equal(t, test.rewrite, suggestion.Rewrite)
+		})
+	}
+}
+
+func TestSuggestionReply(t *testing.T) {
+	single := defaultRuleset.Suggest("whitelist the IP", "", LanguageEnglish)
+	require.NotNil(t, single)
+	require.Contains(t, single.Reply(), "Instead of *whitelist*, perhaps you mean *allowlist*?")
+	require.Contains(t, single.Reply(), "allowlist the IP")
+
+	multiple := defaultRuleset.Suggest("hey guys, that's crazy and insane", "", LanguageEnglish)
+	require.NotNil(t, multiple)
+	reply := multiple.Reply()
+	require.Contains(t, reply, "Some words in your message may not be inclusive:")
+	require.Contains(t, reply, "• *hey guys* → *hey y'all*")
+	require.Contains(t, reply, "• *crazy* → *ridiculous*")
+	require.Contains(t, reply, ">hey y'all, that's ridiculous and ridiculous")
+	require.Contains(t, reply, "Code of Conduct")
+}
+
+func TestRewrite_Masked(t *testing.T) {
+	text := "hey guys, run `make guys`"
+	masked := "hey guys, run            "
+	require.Len(t, masked, len(text))
+
+	suggestion := defaultRuleset.Suggest(masked, "", LanguageEnglish)
+	require.NotNil(t, suggestion)
+	require.Len(t, suggestion.Matches, 1)
+	require.Equal(t, "hey y'all, run `make guys`", Rewrite(text, suggestion.Matches))
+}
@@ -0,0 +1,137 @@
+// Package audit keeps an append-only log of the privileged actions performed through the bot.
+package audit
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// Severities of the audited actions. High severity actions are mirrored to the staff channel.
+const (
+	SeverityInfo = "info"
+	SeverityHigh = "high"
+)
+
+// Outcomes of the audited actions.
+const (
+	OutcomeSuccess = "success"
+	OutcomeFailure = "failure"
+)
+
+// Actors that are not Slack users.
+const (
+	ActorBot = "bot" // Actions performed automatically, like rate limit deletions.
+	ActorAPI = "api" // Actions requested through the HTTP API.
+	ActorCLI = "cli" // Commands run from the command line.
+)
+
+// Entry is an audited action.
+type Entry struct {
+	At       time.Time         `json:"at"`
+	Actor    string            `json:"actor"`
+	Action   string            `json:"action"`
+	Target   string            `json:"target,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+	Outcome  string            `json:"outcome"`
+	Error    string            `json:"error,omitempty"`
+	Severity string            `json:"severity"`
+}
+
+// Filter narrows down the entries returned by Log.Recent. Empty fields match any entry.
+type Filter struct {
+	Actor  string
+	Action string
+}
+
+func (f Filter) matches(e Entry) bool {
+	return (f.Actor == "" || f.Actor == e.Actor) && (f.Action == "" || f.Action == e.Action)
+}
+
+// Log is an append-only audit log, persisted as JSON lines. It is safe for concurrent use.
+type Log struct {
+	mu      sync.RWMutex
+	path    string
+	entries []Entry
+}
+
+// NewLog creates an audit log persisted in the given file path, loading any previously stored entry.
+// An empty path creates an in-memory only log.
+func NewLog(path string) (*Log, error) {
+	l := &Log{path: path}
+	if path == "" {
+		return l, nil
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var e Entry
+		if err := json.Unmarshal(scanner.Bytes(), &e); err != nil {
+			return nil, fmt.Errorf("decode %q line %d: %w", path, line, err)
+		}
+		l.entries = append(l.entries, e)
+	}
+
+	return l, scanner.Err()
+}
+
+// Append adds the entry to the end of the log. Entries are never modified nor removed.
+func (l *Log) Append(e Entry) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = append(l.entries, e)
+	if l.path == "" {
+		return nil
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o750); err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o640)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// Recent returns up to limit entries matching the filter, newest first.
+func (l *Log) Recent(limit int, filter Filter) []Entry {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	var entries []Entry
+	for i := len(l.entries) - 1; i >= 0 && len(entries) < limit; i-- {
+		if filter.matches(l.entries[i]) {
+			entries = append(entries, l.entries[i])
+		}
+	}
+
+	return entries
+}
@@ -0,0 +1,38 @@
+package audit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLog(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit", "audit.jsonl")
+	l, err := NewLog(path)
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, l.Append(Entry{At: now, Actor: "USTAFF", Action: "echo", Target: "CGENERAL", Params: map[string]string{"message": "Hi!"}, Outcome: OutcomeSuccess, Severity: SeverityHigh}))
+	require.NoError(t, l.Append(Entry{At: now, Actor: ActorBot, Action: "rate_limit_message_deleted", Target: "U1", Outcome: OutcomeSuccess, Severity: SeverityInfo}))
+	require.NoError(t, l.Append(Entry{At: now, Actor: "USTAFF", Action: "delete_thread", Target: "CGENERAL", Outcome: OutcomeFailure, Error: "not found", Severity: SeverityHigh}))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Len(t, strings.Split(strings.TrimSpace(string(data)), "\n"), 3, "one JSON line per entry")
+
+	reloaded, err := NewLog(path)
+	require.NoError(t, err)
+
+	entries := reloaded.Recent(10, Filter{})
+	require.Len(t, entries, 3)
+	require.Equal(t, "delete_thread", entries[0].Action, "newest first")
+	require.Equal(t, "Hi!", entries[2].Params["message"])
+
+	require.Len(t, reloaded.Recent(1, Filter{}), 1)
+	require.Len(t, reloaded.Recent(10, Filter{Actor: "USTAFF"}), 2)
+	require.Len(t, reloaded.Recent(10, Filter{Actor: "USTAFF", Action: "echo"}), 1)
+}
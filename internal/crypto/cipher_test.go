@@ -0,0 +1,37 @@
+package crypto
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCipher(t *testing.T) {
+	key := base64.StdEncoding.EncodeToString([]byte(strings.Repeat("k", KeySize)))
+	c, err := NewCipher(key)
+	require.NoError(t, err)
+
+	encrypted, err := c.Encrypt("UREPORTER")
+	require.NoError(t, err)
+	require.NotContains(t, encrypted, "UREPORTER")
+
+	again, err := c.Encrypt("UREPORTER")
+	require.NoError(t, err)
+	require.NotEqual(t, encrypted, again, "nonces must be random")
+
+	decrypted, err := c.Decrypt(encrypted)
+	require.NoError(t, err)
+	require.Equal(t, "UREPORTER", decrypted)
+
+	other, err := NewCipher(base64.StdEncoding.EncodeToString([]byte(strings.Repeat("o", KeySize))))
+	require.NoError(t, err)
+	_, err = other.Decrypt(encrypted)
+	require.Error(t, err, "decrypting with another key must fail")
+
+	_, err = NewCipher(base64.StdEncoding.EncodeToString([]byte("short")))
+	require.Error(t, err)
+	_, err = NewCipher("not base64!")
+	require.Error(t, err)
+}
@@ -0,0 +1,157 @@
+package jobs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultSalaryBands are the default upper limits (in thousands of EUR) of the salary bands used to group posts.
+var DefaultSalaryBands = []int{40, 60, 80, 100}
+
+// Digest summarizes the job posts published within a period of time, grouped by location and salary band.
+type Digest struct {
+	From   time.Time
+	To     time.Time
+	Total  int
+	Groups []DigestGroup
+}
+
+// DigestGroup contains the posts of a location.
+type DigestGroup struct {
+	Location string
+	Total    int
+	Bands    []DigestBand
+}
+
+// DigestBand contains the posts within a salary band.
+type DigestBand struct {
+	Label string
+	Posts []Post
+}
+
+// BuildDigest builds the digest of the non-deleted posts published within [from, to).
+// Salary bands are defined by their upper limits in thousands of EUR, sorted ascending.
+// Posts without salary in EUR are grouped in their own band.
+func BuildDigest(posts []Post, from, to time.Time, bands []int) Digest {
+	d := Digest{From: from, To: to}
+
+	byLocation := make(map[string][][]Post)
+	for _, p := range posts {
+		if p.Deleted() || p.PublishedAt.Before(from) || !p.PublishedAt.Before(to) {
+			continue
+		}
+
+		if byLocation[p.Location] == nil {
+			byLocation[p.Location] = make([][]Post, len(bands)+2) // bands + above the last one + unknown
+		}
+
+		i := salaryBandIndex(p, bands)
+		byLocation[p.Location][i] = append(byLocation[p.Location][i], p)
+		d.Total++
+	}
+
+	for location, postsByBand := range byLocation {
+		g := DigestGroup{Location: location}
+		for i, bandPosts := range postsByBand {
+			if len(bandPosts) == 0 {
+				continue
+			}
+			g.Total += len(bandPosts)
+			g.Bands = append(g.Bands, DigestBand{Label: salaryBandLabel(i, bands), Posts: bandPosts})
+		}
+		d.Groups = append(d.Groups, g)
+	}
+
+	sort.Slice(d.Groups, func(i, j int) bool {
+		if d.Groups[i].Total != d.Groups[j].Total {
+			return d.Groups[i].Total > d.Groups[j].Total
+		}
+		return d.Groups[i].Location < d.Groups[j].Location
+	})
+
+	return d
+}
+
+// salaryBandIndex returns the band the post belongs to, based on the middle of its salary range in EUR.
+func salaryBandIndex(p Post, bands []int) int {
+	if p.MaxSalaryEUR <= 0 {
+		return len(bands) + 1
+	}
+
+	salary := p.MaxSalaryEUR
+	if p.MinSalaryEUR > 0 {
+		salary = (p.MinSalaryEUR + p.MaxSalaryEUR) / 2
+	}
+
+	for i, limit := range bands {
+		if salary < limit {
+			return i
+		}
+	}
+
+	return len(bands)
+}
+
+func salaryBandLabel(i int, bands []int) string {
+	switch {
+	case i > len(bands):
+		return "Salary not in EUR"
+	case len(bands) == 0:
+		return "Any salary"
+	case i == 0:
+		return fmt.Sprintf("< %dK EUR", bands[0])
+	case i == len(bands):
+		return fmt.Sprintf("≥ %dK EUR", bands[len(bands)-1])
+	}
+
+	return fmt.Sprintf("%dK - %dK EUR", bands[i-1], bands[i])
+}
+
+// Format formats the digest as a Slack message.
+func (d Digest) Format() string {
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, ":newspaper: *Job board digest* (%s - %s)\n", d.From.Format("Jan 2"), d.To.AddDate(0, 0, -1).Format("Jan 2"))
+
+	if d.Total == 0 {
+		_, _ = sb.WriteString("No job posts were published last week.")
+		return sb.String()
+	}
+
+	_, _ = fmt.Fprintf(&sb, "%d job posts were published last week:\n", d.Total)
+	for _, g := range d.Groups {
+		_, _ = fmt.Fprintf(&sb, "\n:round_pushpin: *%s* (%d)\n", g.Location, g.Total)
+		for _, b := range g.Bands {
+			links := make([]string, 0, len(b.Posts))
+			for _, p := range b.Posts {
+				links = append(links, fmt.Sprintf("<%s|%s @ %s>", p.Permalink, p.Role, p.Company))
+			}
+			_, _ = fmt.Fprintf(&sb, "• _%s_: %s\n", b.Label, strings.Join(links, ", "))
+		}
+	}
+
+	return sb.String()
+}
+
+// PreviousWeek returns the [from, to) period of the week (Monday to Sunday) before the one t belongs to, in t's location.
+func PreviousWeek(t time.Time) (time.Time, time.Time) {
+	to := startOfWeek(t)
+	return to.AddDate(0, 0, -7), to
+}
+
+// NextDigestTime returns the next Monday at the given hour, in t's location, strictly after t.
+func NextDigestTime(t time.Time, hour int) time.Time {
+	next := startOfWeek(t).Add(time.Duration(hour) * time.Hour)
+	if !next.After(t) {
+		next = next.AddDate(0, 0, 7)
+	}
+
+	return next
+}
+
+// startOfWeek returns the Monday at 00:00 of the week t belongs to.
+func startOfWeek(t time.Time) time.Time {
+	daysSinceMonday := (int(t.Weekday()) + 6) % 7
+	return time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,70 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDigest(t *testing.T) {
+	from := time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC) // Monday
+	to := from.AddDate(0, 0, 7)
+	deletedAt := from.Add(time.Hour)
+
+	posts := []Post{
+		{Role: "Backend", Company: "Acme", Location: "Barcelona", MinSalaryEUR: 50, MaxSalaryEUR: 70, PublishedAt: from},
+		{Role: "Frontend", Company: "Acme", Location: "Barcelona", MinSalaryEUR: -1, MaxSalaryEUR: 35, PublishedAt: from.Add(24 * time.Hour)},
+		{Role: "SRE", Company: "Foo", Location: "Barcelona", MinSalaryEUR: 55, MaxSalaryEUR: 75, PublishedAt: from.Add(48 * time.Hour)},
+		{Role: "CTO", Company: "Bar", Location: "Remote", MinSalaryEUR: 120, MaxSalaryEUR: 150, PublishedAt: from.Add(72 * time.Hour)},
+		{Role: "Data", Company: "Baz", Location: "Remote", PublishedAt: from.Add(72 * time.Hour)},
+		{Role: "Old", Company: "Acme", Location: "Barcelona", MaxSalaryEUR: 50, PublishedAt: from.Add(-time.Hour)},
+		{Role: "Next week", Company: "Acme", Location: "Barcelona", MaxSalaryEUR: 50, PublishedAt: to},
+		{Role: "Deleted", Company: "Acme", Location: "Barcelona", MaxSalaryEUR: 50, PublishedAt: from, DeletedAt: &deletedAt},
+	}
+
+	d := BuildDigest(posts, from, to, DefaultSalaryBands)
+	require.Equal(t, 5, d.Total)
+	require.Len(t, d.Groups, 2)
+
+	barcelona := d.Groups[0]
+	require.Equal(t, "Barcelona", barcelona.Location)
+	require.Equal(t, 3, barcelona.Total)
+	require.Len(t, barcelona.Bands, 2)
+	require.Equal(t, "< 40K EUR", barcelona.Bands[0].Label)
+	require.Equal(t, "60K - 80K EUR", barcelona.Bands[1].Label)
+	require.Len(t, barcelona.Bands[1].Posts, 2)
+
+	remote := d.Groups[1]
+	require.Equal(t, "Remote", remote.Location)
+	require.Equal(t, "≥ 100K EUR", remote.Bands[0].Label)
+	require.Equal(t, "Salary not in EUR", remote.Bands[1].Label)
+
+	msg := d.Format()
+	require.Contains(t, msg, "(May 6 - May 12)")
+	require.Contains(t, msg, "5 job posts")
+	require.Contains(t, msg, "*Barcelona* (3)")
+
+	empty := BuildDigest(nil, from, to, DefaultSalaryBands)
+	require.Contains(t, empty.Format(), "No job posts")
+}
+
+func TestDigestSchedule(t *testing.T) {
+	wednesday := time.Date(2024, 5, 8, 15, 30, 0, 0, time.UTC)
+
+	from, to := PreviousWeek(wednesday)
+	require.Equal(t, time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC), from)
+	require.Equal(t, time.Date(2024, 5, 6, 0, 0, 0, 0, time.UTC), to)
+
+	require.Equal(t, time.Date(2024, 5, 13, 9, 0, 0, 0, time.UTC), NextDigestTime(wednesday, 9))
+
+	mondayEarly := time.Date(2024, 5, 13, 8, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2024, 5, 13, 9, 0, 0, 0, time.UTC), NextDigestTime(mondayEarly, 9))
+
+	mondayOnTime := time.Date(2024, 5, 13, 9, 0, 0, 0, time.UTC)
+	require.Equal(t, time.Date(2024, 5, 20, 9, 0, 0, 0, time.UTC), NextDigestTime(mondayOnTime, 9))
+
+	sunday := time.Date(2024, 5, 12, 23, 0, 0, 0, time.UTC)
+	from, _ = PreviousWeek(sunday)
+	require.Equal(t, time.Date(2024, 4, 29, 0, 0, 0, 0, time.UTC), from)
+}
@@ -0,0 +1,94 @@
+package jobs
+
+import (
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// companySuffixes are legal entity suffixes ignored when comparing company names.
+var companySuffixes = []string{"sl", "slu", "sa", "inc", "ltd", "llc", "gmbh", "bv"}
+
+// FindDuplicate looks for a non-deleted post published after since that is a near-duplicate of candidate.
+// Two posts are near-duplicates when they share the same normalized company, role and link host.
+func (s *Store) FindDuplicate(candidate Post, since time.Time) (Post, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key := duplicateKey(candidate)
+	for i := len(s.posts) - 1; i >= 0; i-- {
+		p := s.posts[i]
+		if p.Deleted() || p.PublishedAt.Before(since) {
+			continue
+		}
+
+		if duplicateKey(p) == key {
+			return p, true
+		}
+	}
+
+	return Post{}, false
+}
+
+// FindDuplicate looks for a pending submission that is a near-duplicate of candidate, as Store.FindDuplicate does.
+func (q *Queue) FindDuplicate(candidate Post) (Submission, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := duplicateKey(candidate)
+	for _, s := range q.submissions {
+		if s.Status == StatusPending && duplicateKey(s.Post) == key {
+			return *s, true
+		}
+	}
+
+	return Submission{}, false
+}
+
+func duplicateKey(p Post) string {
+	return normalizeCompany(p.Company) + "|" + normalizeText(p.Role) + "|" + linkHost(p.Link)
+}
+
+// normalizeText lowercases s, removes accents and collapses any non alphanumeric character into single spaces.
+func normalizeText(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	s, _, _ = transform.String(t, strings.ToLower(s))
+
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), " ")
+}
+
+func normalizeCompany(s string) string {
+	// Dots are dropped so abbreviations like "S.L." are kept as a single word.
+	words := strings.Fields(normalizeText(strings.ReplaceAll(s, ".", "")))
+	for len(words) > 1 && isCompanySuffix(words[len(words)-1]) {
+		words = words[:len(words)-1]
+	}
+
+	return strings.Join(words, "")
+}
+
+func isCompanySuffix(word string) bool {
+	for _, suffix := range companySuffixes {
+		if word == suffix {
+			return true
+		}
+	}
+
+	return false
+}
+
+func linkHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
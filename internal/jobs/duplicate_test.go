@@ -0,0 +1,127 @@
+package jobs
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreFindDuplicate(t *testing.T) {
+	now := time.Now()
+	s, err := NewStore("")
+	require.NoError(t, err)
+
+	require.NoError(t, s.Add(Post{
+		Channel:     "C123",
+		Timestamp:   "1.1",
+		Permalink:   "https://bcneng.slack.com/archives/C123/p11",
+		Role:        "Senior Backend Engineer",
+		Company:     "Acme S.L.",
+		Link:        "https://www.acme.com/jobs/1",
+		PublishedAt: now.Add(-48 * time.Hour),
+	}))
+	require.NoError(t, s.Add(Post{
+		Channel:     "C123",
+		Timestamp:   "2.2",
+		Role:        "Frontend Engineer",
+		Company:     "Acme",
+		Link:        "https://acme.com/jobs/2",
+		PublishedAt: now.Add(-60 * 24 * time.Hour),
+	}))
+
+	tests := []struct {
+		name      string
+		candidate Post
+		since     time.Time
+		duplicate bool
+	}{
+		{
+			name:      "same offer with different casing, accents and link path",
+			candidate: Post{Role: "senior backend  engineer!", Company: "ACME", Link: "https://acme.com/jobs/1?utm_source=foo"},
+			since:     now.Add(-7 * 24 * time.Hour),
+			duplicate: true,
+		},
+		{
+			name:      "different role",
+			candidate: Post{Role: "Data Engineer", Company: "Acme", Link: "https://acme.com/jobs/3"},
+			since:     now.Add(-7 * 24 * time.Hour),
+			duplicate: false,
+		},
+		{
+			name:      "different link host",
+			candidate: Post{Role: "Senior Backend Engineer", Company: "Acme", Link: "https://jobs.lever.co/acme/1"},
+			since:     now.Add(-7 * 24 * time.Hour),
+			duplicate: false,
+		},
+		{
+			name:      "outside of the window",
+			candidate: Post{Role: "Frontend Engineer", Company: "Acme", Link: "https://acme.com/jobs/2"},
+			since:     now.Add(-30 * 24 * time.Hour),
+			duplicate: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, found := s.FindDuplicate(tt.candidate, tt.since)
+			require.Equal(t, tt.duplicate, found)
+			if tt.duplicate {
+				require.Equal(t, "https://bcneng.slack.com/archives/C123/p11", p.Permalink)
+			}
+		})
+	}
+
+	t.Run("deleted posts are not duplicates", func(t *testing.T) {
+		deleted, err := s.MarkDeleted("C123", "1.1", now)
+		require.NoError(t, err)
+		require.True(t, deleted)
+
+		_, found := s.FindDuplicate(Post{Role: "Senior Backend Engineer", Company: "Acme", Link: "https://acme.com"}, now.Add(-7*24*time.Hour))
+		require.False(t, found)
+	})
+}
+
+func TestStorePersistence(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "jobs.json")
+	s, err := NewStore(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Add(Post{Channel: "C123", Timestamp: "1.1", Company: "Acme"}))
+
+	reloaded, err := NewStore(path)
+	require.NoError(t, err)
+	require.Len(t, reloaded.Posts(), 1)
+	require.Equal(t, "Acme", reloaded.Posts()[0].Company)
+}
+
+func TestStoreAddWriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	s, err := NewStore(filepath.Join(dir, "jobs.json"))
+	require.NoError(t, err)
+	require.NoError(t, s.Add(Post{Channel: "C123", Timestamp: "1.1", Company: "Acme"}))
+
+	// A path below a regular file can't be written.
+	s.path = filepath.Join(dir, "jobs.json", "jobs.json")
+	require.Error(t, s.Add(Post{Channel: "C123", Timestamp: "2.2", Company: "Other"}))
+	require.Len(t, s.Posts(), 1, "posts that could not be stored must not be kept")
+}
+
+func TestQueueFindDuplicate(t *testing.T) {
+	q, err := NewQueue("")
+	require.NoError(t, err)
+
+	pending, err := q.Enqueue(Post{Role: "Backend Engineer", Company: "Acme", Link: "https://acme.com/jobs/1"}, time.Now())
+	require.NoError(t, err)
+	decided, err := q.Enqueue(Post{Role: "Frontend Engineer", Company: "Acme", Link: "https://acme.com/jobs/2"}, time.Now())
+	require.NoError(t, err)
+	_, err = q.Decide(decided.ID, Decision{Status: StatusRejected, By: "USTAFF", At: time.Now()})
+	require.NoError(t, err)
+
+	found, ok := q.FindDuplicate(Post{Role: "backend engineer", Company: "ACME S.L.", Link: "https://acme.com/jobs/1?ref=x"})
+	require.True(t, ok)
+	require.Equal(t, pending.ID, found.ID)
+
+	_, ok = q.FindDuplicate(Post{Role: "Frontend Engineer", Company: "Acme", Link: "https://acme.com/jobs/2"})
+	require.False(t, ok, "decided submissions are not duplicates")
+}
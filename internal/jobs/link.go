@@ -0,0 +1,161 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/bcneng/candebot/internal/privacy"
+)
+
+const userAgent = "Mozilla/5.0 (compatible; Candebot; +https://bcneng.org)"
+
+// ErrLinkUnreachable is returned when a job link cannot be fetched at all (DNS, timeout, TLS...).
+var ErrLinkUnreachable = errors.New("job link is unreachable")
+
+// errNonPublicAddress is returned when a job link, or any of its redirects, points to a non public address.
+var errNonPublicAddress = errors.New("non public address")
+
+// nonPublicNetworks are the networks not covered by the net.IP helpers that job links must not point to.
+var nonPublicNetworks = []*net.IPNet{
+	mustParseCIDR("100.64.0.0/10"), // Carrier-grade NAT
+	mustParseCIDR("192.0.0.0/24"),  // IETF protocol assignments
+	mustParseCIDR("198.18.0.0/15"), // Benchmarking
+}
+
+// LinkStatusError is returned when a job link responds with a 4xx or 5xx status code.
+type LinkStatusError struct {
+	StatusCode int
+}
+
+func (e *LinkStatusError) Error() string {
+	return fmt.Sprintf("job link responded with status %d", e.StatusCode)
+}
+
+// LinkChecker verifies job links are reachable, unwrapping redirects and link shorteners.
+type LinkChecker struct {
+	client  *http.Client
+	timeout time.Duration
+}
+
+// NewLinkChecker creates a link checker using the given HTTP client, giving up on a link after the given timeout,
+// redirects and retries included. A nil client uses PublicHTTPClient, as links are fetched by the bot server.
+func NewLinkChecker(client *http.Client, timeout time.Duration) *LinkChecker {
+	if client == nil {
+		client = PublicHTTPClient()
+	}
+
+	return &LinkChecker{client: client, timeout: timeout}
+}
+
+// PublicHTTPClient returns an HTTP client refusing to connect to loopback, private, link-local and other non public
+// addresses, even through redirects.
+func PublicHTTPClient() *http.Client {
+	dialer := &net.Dialer{Control: refuseNonPublicAddress}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil // The dialer must see the final address.
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{Transport: transport}
+}
+
+// Resolve fetches the given link following any redirect, and returns the final URL.
+// Returns ErrLinkUnreachable (wrapped) or a *LinkStatusError if the link is not valid.
+func (c *LinkChecker) Resolve(ctx context.Context, link *url.URL) (*url.URL, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	resp, err := c.do(ctx, http.MethodHead, link)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		// Some servers do not support HEAD requests.
+		resp, err = c.do(ctx, http.MethodGet, link)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrLinkUnreachable, err)
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, &LinkStatusError{StatusCode: resp.StatusCode}
+	}
+
+	return resp.Request.URL, nil
+}
+
+func (c *LinkChecker) do(ctx context.Context, method string, link *url.URL) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, link.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+	_ = resp.Body.Close()
+
+	return resp, nil
+}
+
+// refuseNonPublicAddress is a net.Dialer control refusing to connect to loopback, private, link-local and other
+// non public addresses. It runs once the host is resolved, so it covers redirects and DNS names alike.
+func refuseNonPublicAddress(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return fmt.Errorf("%w: %s", errNonPublicAddress, host)
+	}
+
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsLinkLocalUnicast() ||
+		ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() || ip.IsMulticast() {
+		return false
+	}
+	for _, network := range nonPublicNetworks {
+		if network.Contains(ip) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
+
+// TagLink returns a copy of the given link without known tracking parameters,
+// and with our own utm_source added unless the link already has one.
+func TagLink(link *url.URL) *url.URL {
+	tagged := *link
+	privacy.StripTrackingParams(&tagged)
+
+	if tagged.Query().Get("utm_source") == "" {
+		query := tagged.Query()
+		query.Add("utm_source", "bcneng")
+		tagged.RawQuery = query.Encode()
+	}
+
+	return &tagged
+}
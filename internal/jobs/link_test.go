@@ -0,0 +1,168 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLinkCheckerResolve(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/job", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/short", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/job?ref=short", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/closed", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	mux.HandleFunc("/broken", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	mux.HandleFunc("/no-head", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, _ *http.Request) {
+		time.Sleep(200 * time.Millisecond)
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("/slow-no-head", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(60 * time.Millisecond)
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// The test server listens on loopback, which the default client refuses to connect to.
+	checker := NewLinkChecker(server.Client(), 100*time.Millisecond)
+
+	resolve := func(path string) (*url.URL, error) {
+		link, err := url.Parse(server.URL + path)
+		require.NoError(t, err)
+		return checker.Resolve(context.Background(), link)
+	}
+
+	t.Run("reachable link", func(t *testing.T) {
+		resolved, err := resolve("/job")
+		require.NoError(t, err)
+		require.Equal(t, server.URL+"/job", resolved.String())
+	})
+
+	t.Run("redirects are unwrapped", func(t *testing.T) {
+		resolved, err := resolve("/short")
+		require.NoError(t, err)
+		require.Equal(t, server.URL+"/job?ref=short", resolved.String())
+	})
+
+	t.Run("falls back to GET when HEAD is not allowed", func(t *testing.T) {
+		_, err := resolve("/no-head")
+		require.NoError(t, err)
+	})
+
+	for path, status := range map[string]int{"/closed": http.StatusNotFound, "/broken": http.StatusBadGateway} {
+		t.Run("rejects status "+http.StatusText(status), func(t *testing.T) {
+			_, err := resolve(path)
+			var statusErr *LinkStatusError
+			require.True(t, errors.As(err, &statusErr))
+			require.Equal(t, status, statusErr.StatusCode)
+		})
+	}
+
+	t.Run("timeouts are reported as unreachable", func(t *testing.T) {
+		_, err := resolve("/slow")
+		require.ErrorIs(t, err, ErrLinkUnreachable)
+	})
+
+	t.Run("the timeout covers the GET fallback", func(t *testing.T) {
+		_, err := resolve("/slow-no-head")
+		require.ErrorIs(t, err, ErrLinkUnreachable)
+	})
+
+	t.Run("non public addresses are refused", func(t *testing.T) {
+		link, err := url.Parse(server.URL + "/job")
+		require.NoError(t, err)
+		_, err = NewLinkChecker(nil, time.Second).Resolve(context.Background(), link)
+		require.ErrorIs(t, err, ErrLinkUnreachable)
+		require.Contains(t, err.Error(), errNonPublicAddress.Error())
+	})
+
+	t.Run("redirects to non public addresses are refused", func(t *testing.T) {
+		redirect := httptest.NewServer(http.RedirectHandler(server.URL+"/job", http.StatusFound))
+		defer redirect.Close()
+
+		// Only the redirecting server is allowed, as if it was public.
+		allowed := redirect.Listener.Addr().String()
+		control := func(network, address string, c syscall.RawConn) error {
+			if address == allowed {
+				return nil
+			}
+			return refuseNonPublicAddress(network, address, c)
+		}
+		public := NewLinkChecker(&http.Client{Transport: &http.Transport{DialContext: (&net.Dialer{Control: control}).DialContext}}, time.Second)
+
+		link, err := url.Parse(redirect.URL)
+		require.NoError(t, err)
+		_, err = public.Resolve(context.Background(), link)
+		require.ErrorIs(t, err, ErrLinkUnreachable)
+		require.Contains(t, err.Error(), errNonPublicAddress.Error())
+	})
+}
+
+func TestIsPublicIP(t *testing.T) {
+	for ip, public := range map[string]bool{
+		"8.8.8.8":         true,
+		"2001:4860::8888": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"10.0.0.1":        false,
+		"172.16.5.4":      false,
+		"192.168.1.1":     false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"0.0.0.0":         false,
+		"100.64.0.1":      false,
+		"::ffff:10.0.0.1": false,
+	} {
+		require.Equal(t, public, isPublicIP(net.ParseIP(ip)), ip)
+	}
+}
+
+func TestTagLink(t *testing.T) {
+	tests := []struct {
+		name string
+		link string
+		want string
+	}{
+		{name: "adds utm_source", link: "https://acme.com/jobs/1", want: "https://acme.com/jobs/1?utm_source=bcneng"},
+		{name: "keeps existing utm_source", link: "https://acme.com/jobs/1?utm_source=linkedin", want: "https://acme.com/jobs/1?utm_source=linkedin"},
+		{name: "strips tracking params", link: "https://acme.com/jobs/1?fbclid=abc&id=2", want: "https://acme.com/jobs/1?id=2&utm_source=bcneng"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			link, err := url.Parse(tt.link)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, TagLink(link).String())
+			require.Equal(t, tt.link, link.String(), "original link must not be modified")
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package jobs
+
+// Verdict is the outcome of evaluating a job post against the Policy.
+type Verdict int
+
+const (
+	// Publish means the post can be published right away.
+	Publish Verdict = iota
+	// Review means the post needs to be approved by a staff member before being published.
+	Review
+)
+
+// Policy enforces the job board rules on job post submissions.
+type Policy struct {
+	blocklist              map[string]struct{}
+	agencyPublisher        string
+	agencyRequiresApproval bool
+	moderated              bool
+}
+
+// PolicyConfig defines the job board rules.
+type PolicyConfig struct {
+	// Blocklist contains company or agency names not allowed to post. Compared ignoring case, accents and legal suffixes (S.L., Inc...).
+	Blocklist []string
+	// AgencyPublisher is the publisher type identifying posts published by agencies. Empty if there is no such type.
+	AgencyPublisher string
+	// AgencyRequiresApproval defines whether agency posts need to be approved by staff before being published.
+	AgencyRequiresApproval bool
+	// Moderated defines whether every post needs to be approved by staff before being published.
+	Moderated bool
+}
+
+// NewPolicy creates a policy with the given config.
+func NewPolicy(config PolicyConfig) Policy {
+	p := Policy{
+		blocklist:              make(map[string]struct{}, len(config.Blocklist)),
+		agencyPublisher:        config.AgencyPublisher,
+		agencyRequiresApproval: config.AgencyRequiresApproval,
+		moderated:              config.Moderated,
+	}
+
+	for _, name := range config.Blocklist {
+		p.blocklist[normalizeCompany(name)] = struct{}{}
+	}
+
+	return p
+}
+
+// IsAgency returns true if the post was published by an agency.
+func (p Policy) IsAgency(post Post) bool {
+	return p.agencyPublisher != "" && post.Publisher == p.agencyPublisher
+}
+
+// Evaluate checks the post against the policy.
+// Returns the verdict and a map of field name to violation message. The post must be rejected if there is any violation.
+func (p Policy) Evaluate(post Post) (Verdict, map[string]string) {
+	violations := make(map[string]string)
+	if p.blocked(post.Company) {
+		violations["company"] = "This company is not allowed to publish in the job board. Please contact any Staff member."
+	}
+
+	if !p.IsAgency(post) {
+		return p.verdict(false), violations
+	}
+
+	switch {
+	case post.Client == "":
+		violations["client"] = "Agency posts must name the final client company."
+	case p.blocked(post.Client):
+		violations["client"] = "This company is not allowed to publish in the job board. Please contact any Staff member."
+	case normalizeCompany(post.Client) == normalizeCompany(post.Company):
+		violations["client"] = "The final client can't be the agency itself."
+	}
+
+	return p.verdict(true), violations
+}
+
+func (p Policy) verdict(agency bool) Verdict {
+	if p.moderated || agency && p.agencyRequiresApproval {
+		return Review
+	}
+
+	return Publish
+}
+
+func (p Policy) blocked(company string) bool {
+	_, ok := p.blocklist[normalizeCompany(company)]
+	return ok
+}
@@ -0,0 +1,107 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPolicyEvaluate(t *testing.T) {
+	policy := NewPolicy(PolicyConfig{Blocklist: []string{"Shady Recruiters S.L."}, AgencyPublisher: "Agency"})
+	agencyModerated := NewPolicy(PolicyConfig{AgencyPublisher: "Agency", AgencyRequiresApproval: true})
+	moderated := NewPolicy(PolicyConfig{AgencyPublisher: "Agency", Moderated: true})
+
+	tests := []struct {
+		name       string
+		policy     Policy
+		post       Post
+		verdict    Verdict
+		violations []string
+	}{
+		{
+			name:    "employer post",
+			policy:  policy,
+			post:    Post{Company: "Acme", Publisher: "Employer"},
+			verdict: Publish,
+		},
+		{
+			name:       "blocklisted company",
+			policy:     policy,
+			post:       Post{Company: "shady recruiters", Publisher: "Employer"},
+			verdict:    Publish,
+			violations: []string{"company"},
+		},
+		{
+			name:       "agency post without client",
+			policy:     policy,
+			post:       Post{Company: "Good Agency", Publisher: "Agency"},
+			verdict:    Publish,
+			violations: []string{"client"},
+		},
+		{
+			name:       "agency post naming itself as client",
+			policy:     policy,
+			post:       Post{Company: "Good Agency", Client: "good agency", Publisher: "Agency"},
+			verdict:    Publish,
+			violations: []string{"client"},
+		},
+		{
+			name:       "agency post with a blocklisted client",
+			policy:     policy,
+			post:       Post{Company: "Good Agency", Client: "Shady Recruiters", Publisher: "Agency"},
+			verdict:    Publish,
+			violations: []string{"client"},
+		},
+		{
+			name:    "agency post naming the client",
+			policy:  policy,
+			post:    Post{Company: "Good Agency", Client: "Acme", Publisher: "Agency"},
+			verdict: Publish,
+		},
+		{
+			name:    "agency posts require approval when agencies are moderated",
+			policy:  agencyModerated,
+			post:    Post{Company: "Good Agency", Client: "Acme", Publisher: "Agency"},
+			verdict: Review,
+		},
+		{
+			name:    "employer posts do not require approval when only agencies are moderated",
+			policy:  agencyModerated,
+			post:    Post{Company: "Acme", Publisher: "Employer"},
+			verdict: Publish,
+		},
+		{
+			name:    "every post requires approval when the board is moderated",
+			policy:  moderated,
+			post:    Post{Company: "Acme", Publisher: "Employer"},
+			verdict: Review,
+		},
+		{
+			name:       "violations are reported on moderated boards too",
+			policy:     moderated,
+			post:       Post{Company: "Good Agency", Publisher: "Agency"},
+			verdict:    Review,
+			violations: []string{"client"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, violations := tt.policy.Evaluate(tt.post)
+			require.Equal(t, tt.verdict, verdict)
+			require.Len(t, violations, len(tt.violations))
+			for _, field := range tt.violations {
+				require.NotEmpty(t, violations[field])
+			}
+		})
+	}
+}
+
+func TestPolicyWithoutAgencyPublisher(t *testing.T) {
+	policy := NewPolicy(PolicyConfig{AgencyRequiresApproval: true})
+	require.False(t, policy.IsAgency(Post{Publisher: "Agency"}))
+
+	verdict, violations := policy.Evaluate(Post{Company: "Acme", Publisher: "Agency"})
+	require.Equal(t, Publish, verdict)
+	require.Empty(t, violations)
+}
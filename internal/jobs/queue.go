@@ -0,0 +1,197 @@
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bcneng/candebot/internal/storage"
+)
+
+// Submission statuses.
+const (
+	StatusPending          = "pending"
+	StatusApproved         = "approved"
+	StatusRejected         = "rejected"
+	StatusChangesRequested = "changes_requested"
+)
+
+// ErrSubmissionNotFound is returned when there is no submission with the given ID.
+var ErrSubmissionNotFound = errors.New("job post submission not found")
+
+// ErrSubmissionDecided is returned when deciding on a submission that is not pending anymore.
+var ErrSubmissionDecided = errors.New("job post submission was already decided")
+
+// Submission is a job post waiting for (or already through) staff review.
+type Submission struct {
+	ID          string    `json:"id"`
+	Post        Post      `json:"post"`
+	Status      string    `json:"status"`
+	SubmittedAt time.Time `json:"submitted_at"`
+	Decision    *Decision `json:"decision,omitempty"`
+	// Channel and timestamp of the review message sent to staff.
+	ReviewChannel   string `json:"review_channel,omitempty"`
+	ReviewTimestamp string `json:"review_ts,omitempty"`
+}
+
+// Decision is the outcome of a staff review.
+type Decision struct {
+	Status string    `json:"status"` // One of StatusApproved, StatusRejected or StatusChangesRequested.
+	By     string    `json:"by"`
+	Reason string    `json:"reason,omitempty"` // Rejection reason or requested changes.
+	At     time.Time `json:"at"`
+}
+
+// Queue holds the job post submissions that need staff review. It is safe for concurrent use.
+type Queue struct {
+	mu          sync.Mutex
+	path        string
+	submissions []*Submission
+}
+
+// NewQueue creates a queue persisted as JSON in the given file path, loading any previously stored submission.
+// An empty path creates an in-memory only queue.
+func NewQueue(path string) (*Queue, error) {
+	q := &Queue{path: path}
+	if err := storage.ReadJSON(path, &q.submissions); err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// Enqueue adds a new pending submission for the given post.
+func (q *Queue) Enqueue(post Post, at time.Time) (Submission, error) {
+	id, err := newID()
+	if err != nil {
+		return Submission{}, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	s := &Submission{ID: id, Post: post, Status: StatusPending, SubmittedAt: at}
+	q.submissions = append(q.submissions, s)
+
+	return *s, storage.WriteJSON(q.path, q.submissions)
+}
+
+// Get returns the submission with the given ID.
+func (q *Queue) Get(id string) (Submission, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	s := q.find(id)
+	if s == nil {
+		return Submission{}, ErrSubmissionNotFound
+	}
+
+	return *s, nil
+}
+
+// SetReviewMessage records where the review message of the given submission was sent.
+func (q *Queue) SetReviewMessage(id, channel, ts string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	s := q.find(id)
+	if s == nil {
+		return ErrSubmissionNotFound
+	}
+
+	s.ReviewChannel = channel
+	s.ReviewTimestamp = ts
+
+	return storage.WriteJSON(q.path, q.submissions)
+}
+
+// Decide records the decision on a pending submission, which gets the decision status.
+// Returns ErrSubmissionDecided, along with the submission, if it was already decided.
+// If the decision can't be stored, the submission is left pending so it can be decided again.
+func (q *Queue) Decide(id string, d Decision) (Submission, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	s := q.find(id)
+	if s == nil {
+		return Submission{}, ErrSubmissionNotFound
+	}
+
+	if s.Status != StatusPending {
+		return *s, ErrSubmissionDecided
+	}
+
+	s.Status = d.Status
+	s.Decision = &d
+	if err := storage.WriteJSON(q.path, q.submissions); err != nil {
+		s.Status = StatusPending
+		s.Decision = nil
+		return Submission{}, err
+	}
+
+	return *s, nil
+}
+
+// Reopen sets a decided submission back to pending, dropping its decision, so it can be decided again.
+// Meant for decisions that could not be carried out, like approved posts that could not be published.
+func (q *Queue) Reopen(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	s := q.find(id)
+	if s == nil {
+		return ErrSubmissionNotFound
+	}
+
+	status, decision := s.Status, s.Decision
+	s.Status = StatusPending
+	s.Decision = nil
+	if err := storage.WriteJSON(q.path, q.submissions); err != nil {
+		s.Status, s.Decision = status, decision
+		return err
+	}
+
+	return nil
+}
+
+// Remove drops the submission with the given ID, e.g. when it could not be sent to review.
+func (q *Queue) Remove(id string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for i, s := range q.submissions {
+		if s.ID != id {
+			continue
+		}
+
+		submissions := append(append([]*Submission{}, q.submissions[:i]...), q.submissions[i+1:]...)
+		if err := storage.WriteJSON(q.path, submissions); err != nil {
+			return err
+		}
+		q.submissions = submissions
+		return nil
+	}
+
+	return ErrSubmissionNotFound
+}
+
+func (q *Queue) find(id string) *Submission {
+	for _, s := range q.submissions {
+		if s.ID == id {
+			return s
+		}
+	}
+
+	return nil
+}
+
+func newID() (string, error) {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := NewQueue(path)
+	require.NoError(t, err)
+
+	s, err := q.Enqueue(Post{Company: "Acme"}, time.Now())
+	require.NoError(t, err)
+	require.NotEmpty(t, s.ID)
+	require.Equal(t, StatusPending, s.Status)
+
+	require.NoError(t, q.SetReviewMessage(s.ID, "CSTAFF", "1.1"))
+
+	other, err := q.Enqueue(Post{Company: "Other"}, time.Now())
+	require.NoError(t, err)
+
+	decided, err := q.Decide(s.ID, Decision{Status: StatusApproved, By: "USTAFF", At: time.Now()})
+	require.NoError(t, err)
+	require.Equal(t, StatusApproved, decided.Status)
+	require.Equal(t, "USTAFF", decided.Decision.By)
+	require.Equal(t, "1.1", decided.ReviewTimestamp)
+
+	decided, err = q.Decide(s.ID, Decision{Status: StatusRejected, By: "USTAFF2", At: time.Now()})
+	require.ErrorIs(t, err, ErrSubmissionDecided)
+	require.Equal(t, "USTAFF", decided.Decision.By, "the first decision must be kept")
+
+	changes, err := q.Decide(other.ID, Decision{Status: StatusChangesRequested, By: "USTAFF", Reason: "Add the salary range", At: time.Now()})
+	require.NoError(t, err)
+	require.Equal(t, StatusChangesRequested, changes.Status)
+	require.Equal(t, "Add the salary range", changes.Decision.Reason)
+
+	_, err = q.Decide("unknown", Decision{Status: StatusRejected, By: "USTAFF", At: time.Now()})
+	require.ErrorIs(t, err, ErrSubmissionNotFound)
+
+	reloaded, err := NewQueue(path)
+	require.NoError(t, err)
+	stored, err := reloaded.Get(s.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusApproved, stored.Status)
+	require.Equal(t, "USTAFF", stored.Decision.By)
+	require.Equal(t, "Acme", stored.Post.Company)
+}
+
+func TestQueueDecideWriteFailure(t *testing.T) {
+	dir := t.TempDir()
+	q, err := NewQueue(filepath.Join(dir, "queue.json"))
+	require.NoError(t, err)
+
+	s, err := q.Enqueue(Post{Company: "Acme"}, time.Now())
+	require.NoError(t, err)
+
+	// A path below a regular file can't be written.
+	q.path = filepath.Join(dir, "queue.json", "queue.json")
+	_, err = q.Decide(s.ID, Decision{Status: StatusApproved, By: "USTAFF", At: time.Now()})
+	require.Error(t, err)
+
+	pending, err := q.Get(s.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, pending.Status, "the decision must be rolled back")
+	require.Nil(t, pending.Decision)
+}
+
+func TestQueueReopenAndRemove(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "queue.json")
+	q, err := NewQueue(path)
+	require.NoError(t, err)
+
+	s, err := q.Enqueue(Post{Company: "Acme"}, time.Now())
+	require.NoError(t, err)
+	_, err = q.Decide(s.ID, Decision{Status: StatusApproved, By: "USTAFF", At: time.Now()})
+	require.NoError(t, err)
+
+	require.NoError(t, q.Reopen(s.ID))
+	reopened, err := q.Get(s.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusPending, reopened.Status)
+	require.Nil(t, reopened.Decision)
+
+	require.NoError(t, q.Remove(s.ID))
+	_, err = q.Get(s.ID)
+	require.ErrorIs(t, err, ErrSubmissionNotFound)
+	require.ErrorIs(t, q.Remove(s.ID), ErrSubmissionNotFound)
+	require.ErrorIs(t, q.Reopen(s.ID), ErrSubmissionNotFound)
+
+	reloaded, err := NewQueue(path)
+	require.NoError(t, err)
+	_, err = reloaded.Get(s.ID)
+	require.ErrorIs(t, err, ErrSubmissionNotFound, "removals must be stored")
+}
@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"fmt"
+	"math"
+	"os"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+)
+
+// BaseCurrency is the currency all salaries are normalized to.
+const BaseCurrency = "EUR"
+
+// Salary is a yearly fixed income range, expressed in thousands of Currency units.
+type Salary struct {
+	Min      int // -1 if not specified
+	Max      int
+	Currency string
+}
+
+// Lowest returns the lowest amount of the range.
+func (s Salary) Lowest() int {
+	if s.Min < 0 {
+		return s.Max
+	}
+
+	return s.Min
+}
+
+// BelowFloor returns whether the salary is lower than the floor configured for its currency, along with that floor.
+// Currencies without a floor are never below it.
+func (s Salary) BelowFloor(floors map[string]int) (int, bool) {
+	floor, ok := floors[s.Currency]
+	if !ok {
+		return 0, false
+	}
+
+	return floor, s.Lowest() < floor
+}
+
+// ExchangeRates maps currency codes to the value of one unit of that currency in EUR.
+type ExchangeRates map[string]float64
+
+type exchangeRatesFile struct {
+	Rates ExchangeRates `toml:"rates"`
+}
+
+// LoadExchangeRates reads the exchange rates from a TOML file with the following format:
+//
+//	[rates]
+//	USD = 0.92
+//	GBP = 1.17
+//
+// An empty path returns a table only aware of the base currency.
+func LoadExchangeRates(path string) (ExchangeRates, error) {
+	rates := ExchangeRates{BaseCurrency: 1}
+	if path == "" {
+		return rates, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var f exchangeRatesFile
+	if err := toml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("decode exchange rates file %q: %w", path, err)
+	}
+
+	for currency, rate := range f.Rates {
+		if rate <= 0 {
+			return nil, fmt.Errorf("exchange rate for %s should be positive, got %v", currency, rate)
+		}
+		rates[strings.ToUpper(currency)] = rate
+	}
+
+	return rates, nil
+}
+
+// ToEUR converts the given salary to EUR. Returns false if there is no exchange rate for its currency.
+func (r ExchangeRates) ToEUR(s Salary) (Salary, bool) {
+	rate, ok := r[s.Currency]
+	if !ok {
+		return Salary{}, false
+	}
+
+	eur := Salary{Min: -1, Max: convert(s.Max, rate), Currency: BaseCurrency}
+	if s.Min >= 0 {
+		eur.Min = convert(s.Min, rate)
+	}
+
+	return eur, true
+}
+
+func convert(amount int, rate float64) int {
+	return int(math.Round(float64(amount) * rate))
+}
@@ -0,0 +1,77 @@
+package jobs
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadExchangeRates(t *testing.T) {
+	t.Run("empty path only knows the base currency", func(t *testing.T) {
+		rates, err := LoadExchangeRates("")
+		require.NoError(t, err)
+		require.Equal(t, ExchangeRates{"EUR": 1}, rates)
+	})
+
+	t.Run("loads rates from file", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rates.toml")
+		require.NoError(t, os.WriteFile(path, []byte("[rates]\nusd = 0.9\nGBP = 1.2\n"), 0o600))
+
+		rates, err := LoadExchangeRates(path)
+		require.NoError(t, err)
+		require.Equal(t, ExchangeRates{"EUR": 1, "USD": 0.9, "GBP": 1.2}, rates)
+	})
+
+	t.Run("rejects non positive rates", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rates.toml")
+		require.NoError(t, os.WriteFile(path, []byte("[rates]\nUSD = 0\n"), 0o600))
+
+		_, err := LoadExchangeRates(path)
+		require.Error(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadExchangeRates(filepath.Join(t.TempDir(), "missing.toml"))
+		require.Error(t, err)
+	})
+}
+
+func TestExchangeRatesToEUR(t *testing.T) {
+	rates := ExchangeRates{"EUR": 1, "USD": 0.9}
+
+	eur, ok := rates.ToEUR(Salary{Min: 60, Max: 90, Currency: "USD"})
+	require.True(t, ok)
+	require.Equal(t, Salary{Min: 54, Max: 81, Currency: "EUR"}, eur)
+
+	eur, ok = rates.ToEUR(Salary{Min: -1, Max: 90, Currency: "USD"})
+	require.True(t, ok)
+	require.Equal(t, Salary{Min: -1, Max: 81, Currency: "EUR"}, eur)
+
+	_, ok = rates.ToEUR(Salary{Min: 60, Max: 90, Currency: "CHF"})
+	require.False(t, ok)
+}
+
+func TestSalaryBelowFloor(t *testing.T) {
+	floors := map[string]int{"EUR": 20, "GBP": 18}
+
+	tests := []struct {
+		name   string
+		salary Salary
+		below  bool
+	}{
+		{name: "min above floor", salary: Salary{Min: 30, Max: 40, Currency: "EUR"}, below: false},
+		{name: "min below floor", salary: Salary{Min: 15, Max: 25, Currency: "EUR"}, below: true},
+		{name: "max is used when min is not set", salary: Salary{Min: -1, Max: 19, Currency: "EUR"}, below: true},
+		{name: "each currency has its own floor", salary: Salary{Min: 19, Max: 25, Currency: "GBP"}, below: false},
+		{name: "currencies without floor", salary: Salary{Min: 1, Max: 2, Currency: "USD"}, below: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, below := tt.salary.BelowFloor(floors)
+			require.Equal(t, tt.below, below)
+		})
+	}
+}
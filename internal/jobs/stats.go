@@ -0,0 +1,204 @@
+package jobs
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Stats are the aggregated figures of the job board.
+// Deleted posts are included in every figure, as they were published anyway.
+type Stats struct {
+	Year         int              `json:"year,omitempty"` // Zero if the stats cover all the posts.
+	Total        int              `json:"total"`
+	Deleted      int              `json:"deleted"`
+	DeletionRate float64          `json:"deletion_rate"`
+	PerMonth     []MonthStats     `json:"per_month"`
+	Salaries     []SalaryStats    `json:"salaries"`
+	TopCompanies []CompanyStats   `json:"top_companies"`
+	Publishers   []PublisherStats `json:"publishers"`
+}
+
+// MonthStats is the number of posts published in a month (YYYY-MM).
+type MonthStats struct {
+	Month string `json:"month"`
+	Posts int    `json:"posts"`
+}
+
+// SalaryStats are the median yearly salaries, in thousands, of the posts of a location and currency.
+// MedianMinSalary only considers posts with a min salary, and is zero if there is none.
+type SalaryStats struct {
+	Location        string  `json:"location"`
+	Currency        string  `json:"currency"`
+	Posts           int     `json:"posts"`
+	MedianMinSalary float64 `json:"median_min_salary"`
+	MedianMaxSalary float64 `json:"median_max_salary"`
+}
+
+// CompanyStats is the number of posts published by a company.
+type CompanyStats struct {
+	Company string `json:"company"`
+	Posts   int    `json:"posts"`
+}
+
+// PublisherStats is the number and share of posts of a publisher type (employer, agency...).
+type PublisherStats struct {
+	Publisher string  `json:"publisher"`
+	Posts     int     `json:"posts"`
+	Share     float64 `json:"share"`
+}
+
+// ComputeStats aggregates the posts published in the given year, or all of them if year is zero.
+// Companies are compared ignoring case, accents and legal suffixes, and only the top ones are returned.
+func ComputeStats(posts []Post, year, topCompanies int) Stats {
+	stats := Stats{Year: year}
+
+	perMonth := make(map[string]int)
+	salaries := make(map[[2]string]*salarySamples)
+	companies := make(map[string]*CompanyStats)
+	publishers := make(map[string]int)
+	for _, p := range posts {
+		if year != 0 && p.PublishedAt.Year() != year {
+			continue
+		}
+
+		stats.Total++
+		if p.Deleted() {
+			stats.Deleted++
+		}
+
+		perMonth[p.PublishedAt.Format("2006-01")]++
+		publishers[p.Publisher]++
+
+		key := [2]string{p.Location, p.Currency}
+		if salaries[key] == nil {
+			salaries[key] = &salarySamples{}
+		}
+		salaries[key].add(p)
+
+		company := normalizeCompany(p.Company)
+		if companies[company] == nil {
+			companies[company] = &CompanyStats{Company: p.Company}
+		}
+		companies[company].Posts++
+	}
+
+	if stats.Total == 0 {
+		return stats
+	}
+	stats.DeletionRate = float64(stats.Deleted) / float64(stats.Total)
+
+	for month, n := range perMonth {
+		stats.PerMonth = append(stats.PerMonth, MonthStats{Month: month, Posts: n})
+	}
+	sort.Slice(stats.PerMonth, func(i, j int) bool { return stats.PerMonth[i].Month < stats.PerMonth[j].Month })
+
+	for key, samples := range salaries {
+		stats.Salaries = append(stats.Salaries, SalaryStats{
+			Location:        key[0],
+			Currency:        key[1],
+			Posts:           len(samples.max),
+			MedianMinSalary: median(samples.min),
+			MedianMaxSalary: median(samples.max),
+		})
+	}
+	sort.Slice(stats.Salaries, func(i, j int) bool {
+		if stats.Salaries[i].Location != stats.Salaries[j].Location {
+			return stats.Salaries[i].Location < stats.Salaries[j].Location
+		}
+		return stats.Salaries[i].Currency < stats.Salaries[j].Currency
+	})
+
+	for _, c := range companies {
+		stats.TopCompanies = append(stats.TopCompanies, *c)
+	}
+	sort.Slice(stats.TopCompanies, func(i, j int) bool {
+		if stats.TopCompanies[i].Posts != stats.TopCompanies[j].Posts {
+			return stats.TopCompanies[i].Posts > stats.TopCompanies[j].Posts
+		}
+		return stats.TopCompanies[i].Company < stats.TopCompanies[j].Company
+	})
+	if len(stats.TopCompanies) > topCompanies {
+		stats.TopCompanies = stats.TopCompanies[:topCompanies]
+	}
+
+	for publisher, n := range publishers {
+		stats.Publishers = append(stats.Publishers, PublisherStats{Publisher: publisher, Posts: n, Share: float64(n) / float64(stats.Total)})
+	}
+	sort.Slice(stats.Publishers, func(i, j int) bool {
+		if stats.Publishers[i].Posts != stats.Publishers[j].Posts {
+			return stats.Publishers[i].Posts > stats.Publishers[j].Posts
+		}
+		return stats.Publishers[i].Publisher < stats.Publishers[j].Publisher
+	})
+
+	return stats
+}
+
+// Format formats the stats as a Slack message.
+func (s Stats) Format() string {
+	period := "all time"
+	if s.Year != 0 {
+		period = fmt.Sprint(s.Year)
+	}
+
+	var sb strings.Builder
+	_, _ = fmt.Fprintf(&sb, ":bar_chart: *Job board stats* (%s)\n", period)
+	if s.Total == 0 {
+		_, _ = sb.WriteString("No job posts were published.")
+		return sb.String()
+	}
+
+	_, _ = fmt.Fprintf(&sb, "%d job posts, %d deleted (%.1f%%)\n", s.Total, s.Deleted, s.DeletionRate*100)
+
+	_, _ = sb.WriteString("\n*Posts per month*\n")
+	for _, m := range s.PerMonth {
+		_, _ = fmt.Fprintf(&sb, "• %s: %d\n", m.Month, m.Posts)
+	}
+
+	_, _ = sb.WriteString("\n*Median salary*\n")
+	for _, salary := range s.Salaries {
+		_, _ = fmt.Fprintf(&sb, "• %s (%s): %gK - %gK (%d posts)\n", salary.Location, salary.Currency, salary.MedianMinSalary, salary.MedianMaxSalary, salary.Posts)
+	}
+
+	_, _ = sb.WriteString("\n*Top companies*\n")
+	for _, c := range s.TopCompanies {
+		_, _ = fmt.Fprintf(&sb, "• %s: %d\n", c.Company, c.Posts)
+	}
+
+	_, _ = sb.WriteString("\n*Publishers*\n")
+	for _, p := range s.Publishers {
+		_, _ = fmt.Fprintf(&sb, "• %s: %d (%.1f%%)\n", p.Publisher, p.Posts, p.Share*100)
+	}
+
+	return sb.String()
+}
+
+type salarySamples struct {
+	min []int
+	max []int
+}
+
+func (s *salarySamples) add(p Post) {
+	if p.MinSalary >= 0 {
+		s.min = append(s.min, p.MinSalary)
+	}
+	s.max = append(s.max, p.MaxSalary)
+}
+
+func median(values []int) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]int, len(values))
+	copy(sorted, values)
+	sort.Ints(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return float64(sorted[mid-1]+sorted[mid]) / 2
+	}
+
+	return float64(sorted[mid])
+}
@@ -0,0 +1,38 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestComputeStats(t *testing.T) {
+	jan := time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)
+	feb := time.Date(2024, 2, 15, 10, 0, 0, 0, time.UTC)
+	deletedAt := feb
+
+	posts := []Post{
+		{Company: "Acme S.L.", Location: "Barcelona", Currency: "EUR", MinSalary: 40, MaxSalary: 50, Publisher: "Employer", PublishedAt: jan},
+		{Company: "acme", Location: "Barcelona", Currency: "EUR", MinSalary: -1, MaxSalary: 60, Publisher: "Employer", PublishedAt: jan},
+		{Company: "Foo", Location: "Barcelona", Currency: "EUR", MinSalary: 60, MaxSalary: 80, Publisher: "Agency", PublishedAt: feb, DeletedAt: &deletedAt},
+		{Company: "Bar", Location: "Remote", Currency: "USD", MinSalary: 100, MaxSalary: 120, Publisher: "Employer", PublishedAt: feb},
+		{Company: "Old", Location: "Remote", Currency: "USD", MinSalary: 100, MaxSalary: 120, Publisher: "Employer", PublishedAt: jan.AddDate(-1, 0, 0)},
+	}
+
+	stats := ComputeStats(posts, 2024, 2)
+	require.Equal(t, 4, stats.Total)
+	require.Equal(t, 1, stats.Deleted)
+	require.Equal(t, 0.25, stats.DeletionRate)
+	require.Equal(t, []MonthStats{{Month: "2024-01", Posts: 2}, {Month: "2024-02", Posts: 2}}, stats.PerMonth)
+	require.Equal(t, []SalaryStats{
+		{Location: "Barcelona", Currency: "EUR", Posts: 3, MedianMinSalary: 50, MedianMaxSalary: 60},
+		{Location: "Remote", Currency: "USD", Posts: 1, MedianMinSalary: 100, MedianMaxSalary: 120},
+	}, stats.Salaries)
+	require.Equal(t, []CompanyStats{{Company: "Acme S.L.", Posts: 2}, {Company: "Bar", Posts: 1}}, stats.TopCompanies)
+	require.Equal(t, []PublisherStats{{Publisher: "Employer", Posts: 3, Share: 0.75}, {Publisher: "Agency", Posts: 1, Share: 0.25}}, stats.Publishers)
+	require.Contains(t, stats.Format(), "4 job posts, 1 deleted (25.0%)")
+
+	require.Equal(t, 5, ComputeStats(posts, 0, 10).Total)
+	require.Contains(t, ComputeStats(nil, 2024, 10).Format(), "No job posts")
+}
@@ -0,0 +1,97 @@
+// Package jobs contains the domain logic behind the #hiring-job-board job posts.
+package jobs
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bcneng/candebot/internal/storage"
+)
+
+// Post is a job post published in the jobs channel.
+type Post struct {
+	Channel     string     `json:"channel"`
+	Timestamp   string     `json:"ts"`
+	Permalink   string     `json:"permalink"`
+	Author      string     `json:"author"`
+	AuthorName  string     `json:"author_name"`
+	Role        string     `json:"role"`
+	Company     string     `json:"company"`
+	Client      string     `json:"client,omitempty"` // Final client, for posts published by agencies.
+	Location    string     `json:"location"`
+	Publisher   string     `json:"publisher"`
+	Currency    string     `json:"currency"`
+	MinSalary   int        `json:"min_salary"`
+	MaxSalary   int        `json:"max_salary"`
+	Link        string     `json:"link"`
+	PublishedAt time.Time  `json:"published_at"`
+	DeletedAt   *time.Time `json:"deleted_at,omitempty"`
+
+	// Salary normalized to EUR at publishing time. Zero if there was no exchange rate for Currency.
+	MinSalaryEUR int `json:"min_salary_eur,omitempty"`
+	MaxSalaryEUR int `json:"max_salary_eur,omitempty"`
+}
+
+// Deleted returns true if the post was removed from the jobs channel.
+func (p Post) Deleted() bool {
+	return p.DeletedAt != nil
+}
+
+// Store keeps track of the published job posts. It is safe for concurrent use.
+type Store struct {
+	mu    sync.RWMutex
+	path  string
+	posts []Post
+}
+
+// NewStore creates a store persisted as JSON in the given file path, loading any previously stored post.
+// An empty path creates an in-memory only store.
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	if err := storage.ReadJSON(path, &s.posts); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Add stores a new published post. The post is not kept if it can't be written.
+func (s *Store) Add(p Post) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A full slice expression, so appending never writes over the current posts.
+	posts := append(s.posts[:len(s.posts):len(s.posts)], p)
+	if err := storage.WriteJSON(s.path, posts); err != nil {
+		return err
+	}
+
+	s.posts = posts
+	return nil
+}
+
+// MarkDeleted flags the post identified by its channel and message timestamp as deleted.
+// Returns false if no such post is stored.
+func (s *Store) MarkDeleted(channel, ts string, at time.Time) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i := range s.posts {
+		if s.posts[i].Channel == channel && s.posts[i].Timestamp == ts {
+			s.posts[i].DeletedAt = &at
+			return true, storage.WriteJSON(s.path, s.posts)
+		}
+	}
+
+	return false, nil
+}
+
+// Posts returns a copy of all stored posts, including deleted ones.
+func (s *Store) Posts() []Post {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	posts := make([]Post, len(s.posts))
+	copy(posts, s.posts)
+	return posts
+}
@@ -0,0 +1,191 @@
+package jobs
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bcneng/candebot/internal/storage"
+)
+
+// MaxSubscriptionsPerUser is the maximum number of job alerts a user can have.
+const MaxSubscriptionsPerUser = 10
+
+// ErrTooManySubscriptions is returned when a user reaches MaxSubscriptionsPerUser.
+var ErrTooManySubscriptions = fmt.Errorf("you can't have more than %d job alerts", MaxSubscriptionsPerUser)
+
+// ErrEmptySubscription is returned when subscribing without any criteria.
+var ErrEmptySubscription = errors.New("at least one keyword, a location or a minimum salary is required")
+
+// Subscription is a job alert. Users get a DM for every published post matching all its criteria.
+type Subscription struct {
+	ID        string    `json:"id"`
+	User      string    `json:"user"`
+	Keywords  []string  `json:"keywords,omitempty"`
+	Location  string    `json:"location,omitempty"`
+	MinSalary int       `json:"min_salary,omitempty"` // Yearly, in thousands of Currency.
+	Currency  string    `json:"currency,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Matches returns true if the post matches all the subscription criteria:
+//   - Every keyword is found in the post role or company, ignoring case and accents.
+//   - The location is one of the post locations (e.g. "Remote" matches "Barcelona/Remote").
+//   - The post max salary reaches the minimum salary, converting it to EUR if currencies differ.
+func (s Subscription) Matches(p Post, rates ExchangeRates) bool {
+	text := " " + normalizeText(p.Role+" "+p.Company) + " "
+	for _, k := range s.Keywords {
+		if !strings.Contains(text, " "+normalizeText(k)+" ") {
+			return false
+		}
+	}
+
+	if s.Location != "" && !matchesLocation(p.Location, s.Location) {
+		return false
+	}
+
+	if s.MinSalary <= 0 {
+		return true
+	}
+
+	if s.Currency == p.Currency {
+		return p.MaxSalary >= s.MinSalary
+	}
+
+	floor, ok := rates.ToEUR(Salary{Min: -1, Max: s.MinSalary, Currency: s.Currency})
+	if !ok || p.MaxSalaryEUR <= 0 {
+		return false
+	}
+
+	return p.MaxSalaryEUR >= floor.Max
+}
+
+// String describes the subscription criteria.
+func (s Subscription) String() string {
+	var criteria []string
+	if len(s.Keywords) > 0 {
+		criteria = append(criteria, fmt.Sprintf("keywords: %s", strings.Join(s.Keywords, " ")))
+	}
+	if s.Location != "" {
+		criteria = append(criteria, fmt.Sprintf("location: %s", s.Location))
+	}
+	if s.MinSalary > 0 {
+		criteria = append(criteria, fmt.Sprintf("min salary: %dK %s", s.MinSalary, s.Currency))
+	}
+
+	return strings.Join(criteria, ", ")
+}
+
+func matchesLocation(postLocation, location string) bool {
+	for _, l := range strings.Split(postLocation, "/") {
+		if normalizeText(l) == normalizeText(location) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Subscriptions stores the job alerts of every user. It is safe for concurrent use.
+type Subscriptions struct {
+	mu            sync.RWMutex
+	path          string
+	subscriptions []Subscription
+}
+
+// NewSubscriptions creates a subscriptions store persisted as JSON in the given file path, loading any previously stored subscription.
+// An empty path creates an in-memory only store.
+func NewSubscriptions(path string) (*Subscriptions, error) {
+	s := &Subscriptions{path: path}
+	if err := storage.ReadJSON(path, &s.subscriptions); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Subscribe stores a new subscription, assigning it an ID.
+func (s *Subscriptions) Subscribe(sub Subscription) (Subscription, error) {
+	if len(sub.Keywords) == 0 && sub.Location == "" && sub.MinSalary <= 0 {
+		return Subscription{}, ErrEmptySubscription
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.byUser(sub.User)) >= MaxSubscriptionsPerUser {
+		return Subscription{}, ErrTooManySubscriptions
+	}
+
+	b := make([]byte, 3)
+	if _, err := rand.Read(b); err != nil {
+		return Subscription{}, err
+	}
+	sub.ID = hex.EncodeToString(b)
+
+	s.subscriptions = append(s.subscriptions, sub)
+	return sub, storage.WriteJSON(s.path, s.subscriptions)
+}
+
+// List returns the subscriptions of the given user.
+func (s *Subscriptions) List(user string) []Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.byUser(user)
+}
+
+// Unsubscribe removes the subscription with the given ID from the user, or all of them if id is empty.
+// Returns the number of removed subscriptions.
+func (s *Subscriptions) Unsubscribe(user, id string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	kept := s.subscriptions[:0]
+	for _, sub := range s.subscriptions {
+		if sub.User != user || id != "" && sub.ID != id {
+			kept = append(kept, sub)
+		}
+	}
+
+	removed := len(s.subscriptions) - len(kept)
+	s.subscriptions = kept
+	if removed == 0 {
+		return 0, nil
+	}
+
+	return removed, storage.WriteJSON(s.path, s.subscriptions)
+}
+
+// Matching returns the subscriptions matching the post, at most one per user. Subscriptions of the post author are skipped.
+func (s *Subscriptions) Matching(p Post, rates ExchangeRates) []Subscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	notified := make(map[string]struct{})
+	var matching []Subscription
+	for _, sub := range s.subscriptions {
+		if _, ok := notified[sub.User]; ok || sub.User == p.Author || !sub.Matches(p, rates) {
+			continue
+		}
+		notified[sub.User] = struct{}{}
+		matching = append(matching, sub)
+	}
+
+	return matching
+}
+
+func (s *Subscriptions) byUser(user string) []Subscription {
+	var subs []Subscription
+	for _, sub := range s.subscriptions {
+		if sub.User == user {
+			subs = append(subs, sub)
+		}
+	}
+
+	return subs
+}
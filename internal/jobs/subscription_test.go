@@ -0,0 +1,87 @@
+package jobs
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscriptionMatches(t *testing.T) {
+	rates := ExchangeRates{BaseCurrency: 1, "USD": 0.9}
+	post := Post{Role: "Senior Go Backend Engineer", Company: "Acme", Location: "Barcelona/Remote", Currency: "EUR", MinSalary: 50, MaxSalary: 70, MaxSalaryEUR: 70}
+
+	tests := []struct {
+		name    string
+		sub     Subscription
+		matches bool
+	}{
+		{name: "keywords", sub: Subscription{Keywords: []string{"go", "backend"}}, matches: true},
+		{name: "keywords ignore case", sub: Subscription{Keywords: []string{"BACKEND"}}, matches: true},
+		{name: "keywords match company", sub: Subscription{Keywords: []string{"acme"}}, matches: true},
+		{name: "keywords match whole words", sub: Subscription{Keywords: []string{"back"}}},
+		{name: "every keyword must match", sub: Subscription{Keywords: []string{"go", "frontend"}}},
+		{name: "location", sub: Subscription{Location: "remote"}, matches: true},
+		{name: "other location", sub: Subscription{Location: "Madrid"}},
+		{name: "min salary in the same currency", sub: Subscription{MinSalary: 70, Currency: "EUR"}, matches: true},
+		{name: "min salary above the max salary", sub: Subscription{MinSalary: 71, Currency: "EUR"}},
+		{name: "min salary in another currency", sub: Subscription{MinSalary: 75, Currency: "USD"}, matches: true},
+		{name: "min salary in another currency above the max salary", sub: Subscription{MinSalary: 80, Currency: "USD"}},
+		{name: "min salary in a currency without exchange rate", sub: Subscription{MinSalary: 10, Currency: "JPY"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.matches, tt.sub.Matches(post, rates))
+		})
+	}
+}
+
+func TestSubscriptions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "subscriptions.json")
+	subs, err := NewSubscriptions(path)
+	require.NoError(t, err)
+
+	_, err = subs.Subscribe(Subscription{User: "U1"})
+	require.ErrorIs(t, err, ErrEmptySubscription)
+
+	golang, err := subs.Subscribe(Subscription{User: "U1", Keywords: []string{"go"}})
+	require.NoError(t, err)
+	require.NotEmpty(t, golang.ID)
+	_, err = subs.Subscribe(Subscription{User: "U1", Location: "Barcelona"})
+	require.NoError(t, err)
+	_, err = subs.Subscribe(Subscription{User: "U2", Location: "Barcelona"})
+	require.NoError(t, err)
+	_, err = subs.Subscribe(Subscription{User: "AUTHOR", Location: "Barcelona"})
+	require.NoError(t, err)
+
+	matching := subs.Matching(Post{Author: "AUTHOR", Role: "Go Engineer", Location: "Barcelona"}, nil)
+	require.Len(t, matching, 2, "one per user, skipping the author")
+	require.Equal(t, "U1", matching[0].User)
+	require.Equal(t, "U2", matching[1].User)
+
+	removed, err := subs.Unsubscribe("U2", golang.ID)
+	require.NoError(t, err)
+	require.Zero(t, removed, "users can't remove other users' subscriptions")
+
+	removed, err = subs.Unsubscribe("U1", golang.ID)
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+
+	reloaded, err := NewSubscriptions(path)
+	require.NoError(t, err)
+	require.Len(t, reloaded.List("U1"), 1)
+
+	removed, err = reloaded.Unsubscribe("U1", "")
+	require.NoError(t, err)
+	require.Equal(t, 1, removed)
+	require.Empty(t, reloaded.List("U1"))
+	require.Len(t, reloaded.List("U2"), 1)
+
+	for i := 0; i < MaxSubscriptionsPerUser; i++ {
+		_, err = reloaded.Subscribe(Subscription{User: "U3", Keywords: []string{"go"}})
+		require.NoError(t, err)
+	}
+	_, err = reloaded.Subscribe(Subscription{User: "U3", Keywords: []string{"go"}})
+	require.ErrorIs(t, err, ErrTooManySubscriptions)
+}
@@ -0,0 +1,82 @@
+// Package moderation keeps track of the moderation events of every member, so staff can spot repeat offenders.
+package moderation
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bcneng/candebot/internal/storage"
+)
+
+// Kinds of moderation events.
+const (
+	KindReport    = "report"     // A message of the user was reported.
+	KindRateLimit = "rate_limit" // A message of the user was deleted for exceeding a channel rate limit.
+	KindInclusion = "inclusion"  // The user was warned about non-inclusive language.
+	KindTracking  = "tracking"   // The user was warned about sharing links with tracking parameters.
+	KindWarning   = "warning"    // The user got a formal warning from staff.
+	KindSanction  = "sanction"   // The user was timed out or restricted by staff.
+)
+
+// Record is a moderation event of a user.
+type Record struct {
+	User    string    `json:"user"`
+	Kind    string    `json:"kind"`
+	Channel string    `json:"channel,omitempty"`
+	Detail  string    `json:"detail,omitempty"`
+	At      time.Time `json:"at"`
+}
+
+// History stores the moderation events of every user. It is safe for concurrent use.
+type History struct {
+	mu      sync.RWMutex
+	path    string
+	records []Record
+}
+
+// NewHistory creates a history persisted as JSON in the given file path, loading any previously stored record.
+// An empty path creates an in-memory only history.
+func NewHistory(path string) (*History, error) {
+	h := &History{path: path}
+	if err := storage.ReadJSON(path, &h.records); err != nil {
+		return nil, err
+	}
+
+	return h, nil
+}
+
+// Add stores the record. Returns the number of records of the same user since the given time, including the new one.
+func (h *History) Add(r Record, since time.Time) (int, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.records = append(h.records, r)
+
+	return h.countSince(r.User, since), storage.WriteJSON(h.path, h.records)
+}
+
+// ForUser returns the records of the given user, oldest first.
+func (h *History) ForUser(user string) []Record {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	var records []Record
+	for _, r := range h.records {
+		if r.User == user {
+			records = append(records, r)
+		}
+	}
+
+	return records
+}
+
+func (h *History) countSince(user string, since time.Time) int {
+	var n int
+	for _, r := range h.records {
+		if r.User == user && !r.At.Before(since) {
+			n++
+		}
+	}
+
+	return n
+}
@@ -0,0 +1,40 @@
+package moderation
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.json")
+	h, err := NewHistory(path)
+	require.NoError(t, err)
+
+	now := time.Now()
+	weekAgo := now.AddDate(0, 0, -7)
+
+	n, err := h.Add(Record{User: "U1", Kind: KindReport, At: now.AddDate(0, 0, -10)}, weekAgo)
+	require.NoError(t, err)
+	require.Zero(t, n, "records out of the window are not counted")
+
+	n, err = h.Add(Record{User: "U1", Kind: KindInclusion, At: now.Add(-time.Hour)}, weekAgo)
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	_, err = h.Add(Record{User: "U2", Kind: KindTracking, At: now}, weekAgo)
+	require.NoError(t, err)
+
+	n, err = h.Add(Record{User: "U1", Kind: KindRateLimit, At: now}, weekAgo)
+	require.NoError(t, err)
+	require.Equal(t, 2, n, "only records of the same user are counted")
+
+	reloaded, err := NewHistory(path)
+	require.NoError(t, err)
+	records := reloaded.ForUser("U1")
+	require.Len(t, records, 3)
+	require.Equal(t, KindReport, records[0].Kind)
+	require.Empty(t, reloaded.ForUser("U3"))
+}
@@ -0,0 +1,114 @@
+package moderation
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bcneng/candebot/internal/storage"
+)
+
+// Types of sanctions.
+const (
+	// SanctionTimeout deletes the new top level messages of the user for some hours. Thread replies are allowed.
+	SanctionTimeout = "timeout"
+	// SanctionRestriction deletes every new message of the user, including thread replies, until it is lifted.
+	SanctionRestriction = "restriction"
+)
+
+// ErrNotSanctioned is returned when lifting the sanction of a user who has none.
+var ErrNotSanctioned = errors.New("the user has no active sanction")
+
+// Sanction is a temporary limitation imposed by staff on a user.
+type Sanction struct {
+	User   string    `json:"user"`
+	Type   string    `json:"type"`
+	Reason string    `json:"reason,omitempty"`
+	By     string    `json:"by"`
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until,omitempty"` // Zero if it lasts until lifted.
+}
+
+// Expired returns true if the sanction is over at the given time.
+func (s Sanction) Expired(now time.Time) bool {
+	return !s.Until.IsZero() && !now.Before(s.Until)
+}
+
+// Applies returns true if messages of the given kind are deleted while the sanction is active.
+func (s Sanction) Applies(threadReply bool) bool {
+	return s.Type == SanctionRestriction || !threadReply
+}
+
+// Sanctions stores the active sanction of every user. It is safe for concurrent use.
+type Sanctions struct {
+	mu        sync.RWMutex
+	path      string
+	sanctions map[string]Sanction // By user.
+}
+
+// NewSanctions creates a sanctions store persisted as JSON in the given file path, loading any previously stored sanction.
+// An empty path creates an in-memory only store.
+func NewSanctions(path string) (*Sanctions, error) {
+	s := &Sanctions{path: path, sanctions: make(map[string]Sanction)}
+	if err := storage.ReadJSON(path, &s.sanctions); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Impose sets the sanction of the user, replacing any previous one.
+func (s *Sanctions) Impose(sanction Sanction) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sanctions[sanction.User] = sanction
+
+	return storage.WriteJSON(s.path, s.sanctions)
+}
+
+// Lift removes the sanction of the user, returning it.
+func (s *Sanctions) Lift(user string) (Sanction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sanction, ok := s.sanctions[user]
+	if !ok {
+		return Sanction{}, ErrNotSanctioned
+	}
+	delete(s.sanctions, user)
+
+	return sanction, storage.WriteJSON(s.path, s.sanctions)
+}
+
+// Active returns the sanction of the user, if any and not expired at the given time.
+func (s *Sanctions) Active(user string, now time.Time) (Sanction, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sanction, ok := s.sanctions[user]
+	if !ok || sanction.Expired(now) {
+		return Sanction{}, false
+	}
+
+	return sanction, true
+}
+
+// Expire removes the sanctions expired at the given time, returning them.
+func (s *Sanctions) Expire(now time.Time) ([]Sanction, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var expired []Sanction
+	for user, sanction := range s.sanctions {
+		if sanction.Expired(now) {
+			expired = append(expired, sanction)
+			delete(s.sanctions, user)
+		}
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	return expired, storage.WriteJSON(s.path, s.sanctions)
+}
@@ -0,0 +1,46 @@
+package moderation
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSanctions(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sanctions.json")
+	s, err := NewSanctions(path)
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.NoError(t, s.Impose(Sanction{User: "U1", Type: SanctionTimeout, By: "USTAFF", Since: now, Until: now.Add(2 * time.Hour)}))
+	require.NoError(t, s.Impose(Sanction{User: "U2", Type: SanctionRestriction, By: "USTAFF", Since: now}))
+
+	timeout, ok := s.Active("U1", now.Add(time.Hour))
+	require.True(t, ok)
+	require.True(t, timeout.Applies(false))
+	require.False(t, timeout.Applies(true), "timeouts allow thread replies")
+
+	_, ok = s.Active("U1", now.Add(2*time.Hour))
+	require.False(t, ok, "expired sanctions are not active")
+
+	restriction, ok := s.Active("U2", now.AddDate(1, 0, 0))
+	require.True(t, ok, "restrictions last until lifted")
+	require.True(t, restriction.Applies(true))
+
+	reloaded, err := NewSanctions(path)
+	require.NoError(t, err)
+
+	expired, err := reloaded.Expire(now.Add(3 * time.Hour))
+	require.NoError(t, err)
+	require.Len(t, expired, 1)
+	require.Equal(t, "U1", expired[0].User)
+
+	lifted, err := reloaded.Lift("U2")
+	require.NoError(t, err)
+	require.Equal(t, SanctionRestriction, lifted.Type)
+
+	_, err = reloaded.Lift("U2")
+	require.ErrorIs(t, err, ErrNotSanctioned)
+}
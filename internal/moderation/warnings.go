@@ -0,0 +1,46 @@
+package moderation
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CoCSection is a section of the Code of Conduct formal warnings can refer to.
+type CoCSection struct {
+	Key   string `toml:"key"`
+	Title string `toml:"title"`
+	Text  string `toml:"text"`
+}
+
+// DefaultCoCSections are the Code of Conduct sections offered for warnings when none are configured.
+var DefaultCoCSections = []CoCSection{
+	{Key: "respect", Title: "Be respectful", Text: "Personal attacks, insults and demeaning comments are not allowed. Disagree with ideas, not with people."},
+	{Key: "harassment", Title: "No harassment", Text: "Harassment in any form is not tolerated, including unwelcome private messages, stalking and intimidation."},
+	{Key: "inclusion", Title: "Be inclusive", Text: "Discriminatory jokes and language are not allowed, whatever the gender, origin, religion, age or any other personal trait they target."},
+	{Key: "spam", Title: "No spam", Text: "Unsolicited promotion, repeated messages and cross-posting the same content in several channels are not allowed."},
+	{Key: "channels", Title: "Use the right channel", Text: "Keep conversations on-topic, and use threads to reply to messages."},
+}
+
+// FindCoCSection returns the section with the given key, if any.
+func FindCoCSection(sections []CoCSection, key string) (CoCSection, bool) {
+	for _, s := range sections {
+		if strings.EqualFold(s.Key, key) {
+			return s, true
+		}
+	}
+
+	return CoCSection{}, false
+}
+
+// FormatWarning builds the formal warning sent to a user for breaking the given Code of Conduct section.
+func FormatWarning(section CoCSection, cocLink, note string) string {
+	msg := fmt.Sprintf(":warning: This is a formal warning from the staff for not following our Code of Conduct.\n\n*%s*\n> %s", section.Title, section.Text)
+	if note != "" {
+		msg += fmt.Sprintf("\n\nStaff notes: %s", note)
+	}
+	if cocLink != "" {
+		msg += fmt.Sprintf("\n\nPlease, read the Code of Conduct again: %s", cocLink)
+	}
+
+	return msg + "\nRepeated violations could lead to further actions, like a timeout or being removed from the community."
+}
@@ -0,0 +1,22 @@
+package moderation
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatWarning(t *testing.T) {
+	section, ok := FindCoCSection(DefaultCoCSections, "Spam")
+	require.True(t, ok, "keys are case insensitive")
+
+	msg := FormatWarning(section, "https://bcneng.org/coc", "Promoted the same course in 5 channels")
+	require.Contains(t, msg, "*No spam*\n> Unsolicited promotion")
+	require.Contains(t, msg, "Staff notes: Promoted the same course in 5 channels")
+	require.Contains(t, msg, "https://bcneng.org/coc")
+
+	require.NotContains(t, FormatWarning(section, "", ""), "Staff notes")
+
+	_, ok = FindCoCSection(DefaultCoCSections, "unknown")
+	require.False(t, ok)
+}
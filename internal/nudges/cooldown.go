@@ -0,0 +1,32 @@
+package nudges
+
+import (
+	"sync"
+	"time"
+)
+
+// Cooldown limits how often members are nudged in a channel. It's kept in memory only, and safe for concurrent use.
+type Cooldown struct {
+	mu   sync.Mutex
+	last map[string]time.Time // Last nudge, by channel and user.
+}
+
+// NewCooldown creates an empty cooldown.
+func NewCooldown() *Cooldown {
+	return &Cooldown{last: make(map[string]time.Time)}
+}
+
+// Allow returns true if the user was not nudged in the channel within the given period before now, recording the
+// nudge in that case.
+func (c *Cooldown) Allow(channel, user string, period time.Duration, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := channel + ":" + user
+	if last, ok := c.last[key]; ok && now.Sub(last) < period {
+		return false
+	}
+	c.last[key] = now
+
+	return true
+}
@@ -0,0 +1,19 @@
+package nudges
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCooldown(t *testing.T) {
+	c := NewCooldown()
+	now := time.Now()
+
+	require.True(t, c.Allow("C1", "U1", time.Hour, now))
+	require.False(t, c.Allow("C1", "U1", time.Hour, now.Add(30*time.Minute)))
+	require.True(t, c.Allow("C2", "U1", time.Hour, now), "cooldowns are per channel")
+	require.True(t, c.Allow("C1", "U2", time.Hour, now), "cooldowns are per user")
+	require.True(t, c.Allow("C1", "U1", time.Hour, now.Add(time.Hour)))
+}
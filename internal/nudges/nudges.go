@@ -0,0 +1,81 @@
+// Package nudges keeps track of the members who don't want to be reminded about inclusive language, either about
+// some rules or at all, for a while, and of how helpful the reminders of every rule are.
+package nudges
+
+import (
+	"sync"
+	"time"
+
+	"github.com/bcneng/candebot/internal/storage"
+)
+
+// OptOutDuration is how long nudges are muted for when a member asks so.
+const OptOutDuration = 30 * 24 * time.Hour
+
+// Preference is what a member chose about nudges.
+type Preference struct {
+	SnoozedUntil time.Time            `json:"snoozed_until,omitempty"` // All nudges are muted until then.
+	Rules        map[string]time.Time `json:"rules,omitempty"`         // Until when nudges are muted, by rule pattern.
+}
+
+// Preferences stores the preference of every member. It is safe for concurrent use.
+type Preferences struct {
+	mu          sync.RWMutex
+	path        string
+	preferences map[string]Preference // By user.
+}
+
+// NewPreferences creates a preferences store persisted as JSON in the given file path, loading any previously stored preference.
+// An empty path creates an in-memory only store.
+func NewPreferences(path string) (*Preferences, error) {
+	p := &Preferences{path: path, preferences: make(map[string]Preference)}
+	if err := storage.ReadJSON(path, &p.preferences); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// OptOut mutes the nudges of the given rule for the user until the given time.
+func (p *Preferences) OptOut(user, rule string, until time.Time) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pref := p.preferences[user]
+	if pref.Rules == nil {
+		pref.Rules = make(map[string]time.Time)
+	}
+	pref.Rules[rule] = until
+	p.preferences[user] = pref
+
+	return storage.WriteJSON(p.path, p.preferences)
+}
+
+// Snooze mutes all the nudges for the user until the given time.
+func (p *Preferences) Snooze(user string, until time.Time) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	pref := p.preferences[user]
+	pref.SnoozedUntil = until
+	p.preferences[user] = pref
+
+	return storage.WriteJSON(p.path, p.preferences)
+}
+
+// Snoozed returns true if all the nudges are muted for the user at the given time.
+func (p *Preferences) Snoozed(user string, now time.Time) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	return now.Before(p.preferences[user].SnoozedUntil)
+}
+
+// Muted returns true if the nudges of the given rule are muted for the user at the given time, including when all are.
+func (p *Preferences) Muted(user, rule string, now time.Time) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	pref := p.preferences[user]
+	return now.Before(pref.SnoozedUntil) || now.Before(pref.Rules[rule])
+}
@@ -0,0 +1,34 @@
+package nudges
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreferences(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nudges.json")
+	p, err := NewPreferences(path)
+	require.NoError(t, err)
+
+	now := time.Now()
+	require.False(t, p.Muted("U1", "guys", now))
+
+	require.NoError(t, p.OptOut("U1", "guys", now.Add(OptOutDuration)))
+	require.True(t, p.Muted("U1", "guys", now))
+	require.False(t, p.Muted("U1", "crazy", now), "only the given rule is muted")
+	require.False(t, p.Muted("U2", "guys", now), "only the given user is muted")
+	require.False(t, p.Muted("U1", "guys", now.Add(OptOutDuration)), "opt-outs expire")
+
+	require.NoError(t, p.Snooze("U2", now.Add(time.Hour)))
+	require.True(t, p.Snoozed("U2", now))
+	require.True(t, p.Muted("U2", "crazy", now))
+	require.False(t, p.Snoozed("U2", now.Add(2*time.Hour)))
+
+	reloaded, err := NewPreferences(path)
+	require.NoError(t, err)
+	require.True(t, reloaded.Muted("U1", "guys", now))
+	require.True(t, reloaded.Snoozed("U2", now))
+}
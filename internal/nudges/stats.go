@@ -0,0 +1,129 @@
+package nudges
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/bcneng/candebot/internal/storage"
+)
+
+// RuleStats are the numbers of a rule: how many times it nudged members, and what they thought about it.
+type RuleStats struct {
+	Rule           string         `json:"rule"`
+	Matches        int            `json:"matches"`
+	Helpful        int            `json:"helpful"`
+	FalsePositives int            `json:"false_positives"`
+	Channels       map[string]int `json:"channels,omitempty"` // Matches by channel ID.
+}
+
+// FalsePositiveRatio returns the ratio of feedback saying the rule was wrong, from 0 to 1. Zero if there is no feedback.
+func (s RuleStats) FalsePositiveRatio() float64 {
+	feedback := s.Helpful + s.FalsePositives
+	if feedback == 0 {
+		return 0
+	}
+
+	return float64(s.FalsePositives) / float64(feedback)
+}
+
+// TopChannels returns the IDs of the channels with more matches, at most the given number of them.
+func (s RuleStats) TopChannels(limit int) []string {
+	channels := make([]string, 0, len(s.Channels))
+	for c := range s.Channels {
+		channels = append(channels, c)
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		if s.Channels[channels[i]] != s.Channels[channels[j]] {
+			return s.Channels[channels[i]] > s.Channels[channels[j]]
+		}
+		return channels[i] < channels[j]
+	})
+
+	if len(channels) > limit {
+		channels = channels[:limit]
+	}
+
+	return channels
+}
+
+// Stats stores the numbers of every rule. It is safe for concurrent use.
+type Stats struct {
+	mu    sync.RWMutex
+	path  string
+	rules map[string]RuleStats // By rule pattern.
+}
+
+// NewStats creates a stats store persisted as JSON in the given file path, loading any previously stored numbers.
+// An empty path creates an in-memory only store.
+func NewStats(path string) (*Stats, error) {
+	s := &Stats{path: path, rules: make(map[string]RuleStats)}
+	if err := storage.ReadJSON(path, &s.rules); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// RecordMatch counts a nudge of the given rules in the channel.
+func (s *Stats) RecordMatch(channel string, rules ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, rule := range rules {
+		rs := s.rule(rule)
+		rs.Matches++
+		if rs.Channels == nil {
+			rs.Channels = make(map[string]int)
+		}
+		rs.Channels[channel]++
+		s.rules[rule] = rs
+	}
+
+	return storage.WriteJSON(s.path, s.rules)
+}
+
+// RecordFeedback counts the opinion of a member about a nudge of the given rules.
+func (s *Stats) RecordFeedback(helpful bool, rules ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, rule := range rules {
+		rs := s.rule(rule)
+		if helpful {
+			rs.Helpful++
+		} else {
+			rs.FalsePositives++
+		}
+		s.rules[rule] = rs
+	}
+
+	return storage.WriteJSON(s.path, s.rules)
+}
+
+// All returns the numbers of every rule, the ones with more matches first.
+func (s *Stats) All() []RuleStats {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	all := make([]RuleStats, 0, len(s.rules))
+	for _, rs := range s.rules {
+		all = append(all, rs)
+	}
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].Matches != all[j].Matches {
+			return all[i].Matches > all[j].Matches
+		}
+		return all[i].Rule < all[j].Rule
+	})
+
+	return all
+}
+
+func (s *Stats) rule(rule string) RuleStats {
+	rs, ok := s.rules[rule]
+	if !ok {
+		rs.Rule = rule
+	}
+
+	return rs
+}
@@ -0,0 +1,37 @@
+package nudges
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStats(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stats.json")
+	s, err := NewStats(path)
+	require.NoError(t, err)
+
+	require.NoError(t, s.RecordMatch("C1", "guys", "crazy"))
+	require.NoError(t, s.RecordMatch("C2", "guys"))
+	require.NoError(t, s.RecordMatch("C2", "guys"))
+	require.NoError(t, s.RecordFeedback(true, "guys", "crazy"))
+	require.NoError(t, s.RecordFeedback(false, "guys"))
+	require.NoError(t, s.RecordFeedback(false, "guys"))
+	require.NoError(t, s.RecordFeedback(false, "guys"))
+
+	reloaded, err := NewStats(path)
+	require.NoError(t, err)
+	all := reloaded.All()
+	require.Len(t, all, 2)
+
+	require.Equal(t, "guys", all[0].Rule, "rules with more matches go first")
+	require.Equal(t, 3, all[0].Matches)
+	require.Equal(t, 0.75, all[0].FalsePositiveRatio())
+	require.Equal(t, []string{"C2", "C1"}, all[0].TopChannels(3))
+	require.Equal(t, []string{"C2"}, all[0].TopChannels(1))
+
+	require.Equal(t, "crazy", all[1].Rule)
+	require.Zero(t, all[1].FalsePositiveRatio())
+	require.Zero(t, RuleStats{}.FalsePositiveRatio(), "no feedback, no false positives")
+}
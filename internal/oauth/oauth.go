@@ -0,0 +1,130 @@
+// Package oauth keeps the user tokens members grant the bot through the Slack OAuth flow, so it can act on their behalf.
+package oauth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bcneng/candebot/internal/crypto"
+	"github.com/bcneng/candebot/internal/storage"
+)
+
+// AuthorizeURL is the Slack URL members are sent to in order to grant the bot a user token.
+const AuthorizeURL = "https://slack.com/oauth/v2/authorize"
+
+var (
+	// ErrNoToken is returned when the member didn't grant the bot any token.
+	ErrNoToken = errors.New("the member has not authorized the bot")
+	// ErrInvalidState is returned when the state of an OAuth callback was not issued by the bot, or is expired.
+	ErrInvalidState = errors.New("invalid or expired OAuth state")
+)
+
+// Tokens stores the user token of every member, encrypted. It is safe for concurrent use.
+type Tokens struct {
+	mu     sync.RWMutex
+	path   string
+	cipher *crypto.Cipher
+	tokens map[string]string // Encrypted, by user.
+}
+
+// NewTokens creates a tokens store persisted as JSON in the given file path, loading any previously stored token.
+// Tokens are encrypted with the given cipher. An empty path creates an in-memory only store.
+func NewTokens(path string, cipher *crypto.Cipher) (*Tokens, error) {
+	t := &Tokens{path: path, cipher: cipher, tokens: make(map[string]string)}
+	if err := storage.ReadJSON(path, &t.tokens); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Set stores the token of the user, replacing any previous one.
+func (t *Tokens) Set(user, token string) error {
+	encrypted, err := t.cipher.Encrypt(token)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tokens[user] = encrypted
+
+	return storage.WriteJSON(t.path, t.tokens)
+}
+
+// Get returns the token of the user, or ErrNoToken if there is none.
+func (t *Tokens) Get(user string) (string, error) {
+	t.mu.RLock()
+	encrypted, ok := t.tokens[user]
+	t.mu.RUnlock()
+	if !ok {
+		return "", ErrNoToken
+	}
+
+	return t.cipher.Decrypt(encrypted)
+}
+
+// Delete removes the token of the user, if any.
+func (t *Tokens) Delete(user string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.tokens, user)
+
+	return storage.WriteJSON(t.path, t.tokens)
+}
+
+// AuthorizeLink returns the link to grant the bot a user token with the given scopes. The state identifies the member
+// along the flow, see SignState.
+func AuthorizeLink(clientID, redirectURL, state string, scopes ...string) string {
+	params := url.Values{}
+	params.Set("client_id", clientID)
+	params.Set("user_scope", strings.Join(scopes, ","))
+	params.Set("redirect_uri", redirectURL)
+	params.Set("state", state)
+
+	return AuthorizeURL + "?" + params.Encode()
+}
+
+// SignState returns an OAuth state for the user, valid until the given time. It's signed with the key, so the bot can
+// trust the user it carries when it comes back in the callback.
+func SignState(key []byte, user string, expires time.Time) string {
+	payload := fmt.Sprintf("%s.%d", user, expires.Unix())
+	return payload + "." + base64.RawURLEncoding.EncodeToString(stateSignature(key, payload))
+}
+
+// VerifyState returns the user of a state signed with SignState, or ErrInvalidState if the signature doesn't match
+// or the state is expired at the given time.
+func VerifyState(key []byte, state string, now time.Time) (string, error) {
+	parts := strings.Split(state, ".")
+	if len(parts) != 3 {
+		return "", ErrInvalidState
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil || !hmac.Equal(signature, stateSignature(key, parts[0]+"."+parts[1])) {
+		return "", ErrInvalidState
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || !now.Before(time.Unix(expires, 0)) {
+		return "", ErrInvalidState
+	}
+
+	return parts[0], nil
+}
+
+func stateSignature(key []byte, payload string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	return mac.Sum(nil)
+}
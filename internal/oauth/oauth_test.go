@@ -0,0 +1,71 @@
+package oauth
+
+import (
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bcneng/candebot/internal/crypto"
+	"github.com/stretchr/testify/require"
+)
+
+const testKey = "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=" // 32 bytes
+
+func TestTokens(t *testing.T) {
+	cipher, err := crypto.NewCipher(testKey)
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	tokens, err := NewTokens(path, cipher)
+	require.NoError(t, err)
+
+	_, err = tokens.Get("U1")
+	require.ErrorIs(t, err, ErrNoToken)
+
+	require.NoError(t, tokens.Set("U1", "xoxp-secret"))
+
+	reloaded, err := NewTokens(path, cipher)
+	require.NoError(t, err)
+	token, err := reloaded.Get("U1")
+	require.NoError(t, err)
+	require.Equal(t, "xoxp-secret", token)
+	require.NotContains(t, reloaded.tokens["U1"], "xoxp-secret", "tokens are stored encrypted")
+
+	require.NoError(t, reloaded.Delete("U1"))
+	_, err = reloaded.Get("U1")
+	require.ErrorIs(t, err, ErrNoToken)
+}
+
+func TestState(t *testing.T) {
+	key := []byte("signing-secret")
+	now := time.Now()
+	state := SignState(key, "U1", now.Add(time.Minute))
+
+	user, err := VerifyState(key, state, now)
+	require.NoError(t, err)
+	require.Equal(t, "U1", user)
+
+	_, err = VerifyState(key, state, now.Add(time.Hour))
+	require.ErrorIs(t, err, ErrInvalidState, "expired")
+
+	_, err = VerifyState([]byte("other-secret"), state, now)
+	require.ErrorIs(t, err, ErrInvalidState, "signed with another key")
+
+	_, err = VerifyState(key, strings.Replace(state, "U1", "U2", 1), now)
+	require.ErrorIs(t, err, ErrInvalidState, "tampered")
+
+	_, err = VerifyState(key, "garbage", now)
+	require.ErrorIs(t, err, ErrInvalidState)
+}
+
+func TestAuthorizeLink(t *testing.T) {
+	link, err := url.Parse(AuthorizeLink("123.456", "https://bot.example.com/oauth/callback", "state", "chat:write"))
+	require.NoError(t, err)
+	require.Equal(t, "slack.com", link.Host)
+	require.Equal(t, "123.456", link.Query().Get("client_id"))
+	require.Equal(t, "chat:write", link.Query().Get("user_scope"))
+	require.Equal(t, "https://bot.example.com/oauth/callback", link.Query().Get("redirect_uri"))
+	require.Equal(t, "state", link.Query().Get("state"))
+}
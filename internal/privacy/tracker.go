@@ -56,6 +56,16 @@ func stripTracking(rawURL string) (cleaned string, stripped bool, err error) {
 		return rawURL, false, err
 	}
 
+	if !StripTrackingParams(u) {
+		return rawURL, false, nil
+	}
+
+	return u.String(), true, nil
+}
+
+// StripTrackingParams removes known tracking parameters from the given URL in place.
+// Returns whether any parameter was removed.
+func StripTrackingParams(u *url.URL) bool {
 	query := u.Query()
 	modified := false
 
@@ -66,12 +76,11 @@ func stripTracking(rawURL string) (cleaned string, stripped bool, err error) {
 		}
 	}
 
-	if !modified {
-		return rawURL, false, nil
+	if modified {
+		u.RawQuery = query.Encode()
 	}
 
-	u.RawQuery = query.Encode()
-	return u.String(), true, nil
+	return modified
 }
 
 // SanitizedURL processes all URLs in text and returns cleaned versions with tracking removed.
@@ -0,0 +1,239 @@
+// Package reports keeps track of the message reports sent by members, handled by staff as cases.
+package reports
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/bcneng/candebot/internal/storage"
+)
+
+// Case statuses.
+const (
+	StatusOpen         = "open"
+	StatusAcknowledged = "acknowledged"
+	StatusResolved     = "resolved"
+)
+
+// Event types recorded in the case history.
+const (
+	EventOpened         = "opened"
+	EventAssigned       = "assigned"
+	EventMessageDeleted = "message_deleted"
+	EventUserWarned     = "user_warned"
+	EventResolved       = "resolved"
+	// EventReporterRevealed is recorded whenever a staff member reveals the identity of an anonymous reporter.
+	EventReporterRevealed = "reporter_revealed"
+)
+
+// ErrCaseNotFound is returned when there is no case with the given ID.
+var ErrCaseNotFound = errors.New("report case not found")
+
+// ErrCaseResolved is returned when changing a case that is already resolved.
+var ErrCaseResolved = errors.New("report case was already resolved")
+
+// Case is a message report handled by staff.
+type Case struct {
+	ID           int    `json:"id"`
+	Status       string `json:"status"`
+	Reporter     string `json:"reporter,omitempty"` // Empty for anonymous reports.
+	ReporterName string `json:"reporter_name,omitempty"`
+	Anonymous    bool   `json:"anonymous,omitempty"`
+	// EncryptedReporter is the reporter user ID of anonymous reports, encrypted with crypto.Cipher.
+	EncryptedReporter string `json:"encrypted_reporter,omitempty"`
+	Reason            string `json:"reason"`
+	Scale             string `json:"scale"` // How hurtful the message felt to the reporter, from 1 to 5.
+	// Reported message.
+	Channel          string `json:"channel"`
+	MessageTimestamp string `json:"message_ts"`
+	MessageAuthor    string `json:"message_author,omitempty"`
+	// Snapshot of the reported message at report time, kept as evidence in case it is edited or deleted. Nil if it could not be captured.
+	Snapshot *Snapshot `json:"snapshot,omitempty"`
+	// Staff member handling the case.
+	AssignedTo string    `json:"assigned_to,omitempty"`
+	Resolution string    `json:"resolution,omitempty"` // Resolution notes.
+	CreatedAt  time.Time `json:"created_at"`
+	Events     []Event   `json:"events"`
+	// Channel and timestamp of the notification sent to staff.
+	NotificationChannel   string `json:"notification_channel,omitempty"`
+	NotificationTimestamp string `json:"notification_ts,omitempty"`
+}
+
+// Snapshot is a copy of the reported message and the messages around it.
+type Snapshot struct {
+	Message    SnapshotMessage   `json:"message"`
+	Context    []SnapshotMessage `json:"context,omitempty"` // Chronological, including the reported message.
+	CapturedAt time.Time         `json:"captured_at"`
+}
+
+// SnapshotMessage is a copy of a Slack message.
+type SnapshotMessage struct {
+	Author    string    `json:"author"`
+	Text      string    `json:"text"`
+	Timestamp string    `json:"ts"`
+	PostedAt  time.Time `json:"posted_at"`
+	// EditedAt is the time of the last edit, if the message was edited. Slack does not provide previous versions.
+	EditedAt *time.Time `json:"edited_at,omitempty"`
+	EditedBy string     `json:"edited_by,omitempty"`
+}
+
+// HasEvent returns true if the case history contains an event of the given type.
+func (c Case) HasEvent(eventType string) bool {
+	for _, e := range c.Events {
+		if e.Type == eventType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Event is an entry of the case history.
+type Event struct {
+	Type string    `json:"type"`
+	By   string    `json:"by"`
+	Note string    `json:"note,omitempty"`
+	At   time.Time `json:"at"`
+}
+
+// Store holds the report cases. It is safe for concurrent use.
+type Store struct {
+	mu    sync.Mutex
+	path  string
+	cases []*Case
+}
+
+// NewStore creates a store persisted as JSON in the given file path, loading any previously stored case.
+// An empty path creates an in-memory only store.
+func NewStore(path string) (*Store, error) {
+	s := &Store{path: path}
+	if err := storage.ReadJSON(path, &s.cases); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// Open creates a new open case, assigning it the next sequential ID.
+func (s *Store) Open(c Case) (Case, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c.ID = len(s.cases) + 1
+	c.Status = StatusOpen
+	c.Events = []Event{{Type: EventOpened, By: c.Reporter, At: c.CreatedAt}}
+	s.cases = append(s.cases, &c)
+
+	return c, storage.WriteJSON(s.path, s.cases)
+}
+
+// Get returns the case with the given ID.
+func (s *Store) Get(id int) (Case, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.find(id)
+	if c == nil {
+		return Case{}, ErrCaseNotFound
+	}
+
+	return *c, nil
+}
+
+// SetNotification records the staff notification of the case, so it can be updated later on.
+func (s *Store) SetNotification(id int, channel, ts string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.find(id)
+	if c == nil {
+		return ErrCaseNotFound
+	}
+
+	c.NotificationChannel = channel
+	c.NotificationTimestamp = ts
+
+	return storage.WriteJSON(s.path, s.cases)
+}
+
+// Assign assigns the case to the given staff member, acknowledging it.
+func (s *Store) Assign(id int, by string, at time.Time) (Case, error) {
+	return s.update(id, Event{Type: EventAssigned, By: by, At: at}, func(c *Case) {
+		c.Status = StatusAcknowledged
+		c.AssignedTo = by
+	})
+}
+
+// Record adds an event to the case history, like an action taken by staff.
+func (s *Store) Record(id int, e Event) (Case, error) {
+	return s.update(id, e, func(*Case) {})
+}
+
+// Resolve resolves the case with the given notes. The case is assigned to the resolver if it was not assigned yet.
+func (s *Store) Resolve(id int, by, notes string, at time.Time) (Case, error) {
+	return s.update(id, Event{Type: EventResolved, By: by, Note: notes, At: at}, func(c *Case) {
+		c.Status = StatusResolved
+		c.Resolution = notes
+		if c.AssignedTo == "" {
+			c.AssignedTo = by
+		}
+	})
+}
+
+// Audit adds an event to the case history, even if the case is already resolved.
+func (s *Store) Audit(id int, e Event) (Case, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.find(id)
+	if c == nil {
+		return Case{}, ErrCaseNotFound
+	}
+
+	c.Events = append(c.Events, e)
+
+	return *c, storage.WriteJSON(s.path, s.cases)
+}
+
+// Cases returns a copy of all the cases.
+func (s *Store) Cases() []Case {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cases := make([]Case, 0, len(s.cases))
+	for _, c := range s.cases {
+		cases = append(cases, *c)
+	}
+
+	return cases
+}
+
+// update applies the change and records the event, unless the case is already resolved.
+// The current case is returned along with ErrCaseResolved in that case.
+func (s *Store) update(id int, e Event, change func(c *Case)) (Case, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	c := s.find(id)
+	if c == nil {
+		return Case{}, ErrCaseNotFound
+	}
+
+	if c.Status == StatusResolved {
+		return *c, ErrCaseResolved
+	}
+
+	change(c)
+	c.Events = append(c.Events, e)
+
+	return *c, storage.WriteJSON(s.path, s.cases)
+}
+
+func (s *Store) find(id int) *Case {
+	if id < 1 || id > len(s.cases) {
+		return nil
+	}
+
+	return s.cases[id-1]
+}
@@ -0,0 +1,78 @@
+package reports
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports.json")
+	s, err := NewStore(path)
+	require.NoError(t, err)
+
+	c, err := s.Open(Case{Reporter: "UREPORTER", Reason: "Insults", Channel: "C1", MessageTimestamp: "1.1", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	require.Equal(t, 1, c.ID)
+	require.Equal(t, StatusOpen, c.Status)
+	require.Len(t, c.Events, 1)
+
+	other, err := s.Open(Case{Reporter: "UREPORTER", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	require.Equal(t, 2, other.ID)
+
+	require.NoError(t, s.SetNotification(c.ID, "CSTAFF", "2.2"))
+
+	c, err = s.Assign(c.ID, "USTAFF", time.Now())
+	require.NoError(t, err)
+	require.Equal(t, StatusAcknowledged, c.Status)
+	require.Equal(t, "USTAFF", c.AssignedTo)
+
+	c, err = s.Record(c.ID, Event{Type: EventMessageDeleted, By: "USTAFF", At: time.Now()})
+	require.NoError(t, err)
+	require.Equal(t, EventMessageDeleted, c.Events[len(c.Events)-1].Type)
+
+	c, err = s.Resolve(c.ID, "USTAFF2", "Message removed and user warned", time.Now())
+	require.NoError(t, err)
+	require.Equal(t, StatusResolved, c.Status)
+	require.Equal(t, "USTAFF", c.AssignedTo, "the assignee must be kept")
+	require.Equal(t, "Message removed and user warned", c.Resolution)
+
+	_, err = s.Assign(c.ID, "USTAFF2", time.Now())
+	require.ErrorIs(t, err, ErrCaseResolved)
+
+	other, err = s.Resolve(other.ID, "USTAFF2", "Not a COC violation", time.Now())
+	require.NoError(t, err)
+	require.Equal(t, "USTAFF2", other.AssignedTo, "unassigned cases are assigned to the resolver")
+
+	_, err = s.Get(3)
+	require.ErrorIs(t, err, ErrCaseNotFound)
+
+	reloaded, err := NewStore(path)
+	require.NoError(t, err)
+	stored, err := reloaded.Get(c.ID)
+	require.NoError(t, err)
+	require.Equal(t, StatusResolved, stored.Status)
+	require.Equal(t, "2.2", stored.NotificationTimestamp)
+	require.Len(t, stored.Events, 4)
+	require.Len(t, reloaded.Cases(), 2)
+}
+
+func TestStoreAudit(t *testing.T) {
+	s, err := NewStore("")
+	require.NoError(t, err)
+
+	c, err := s.Open(Case{Anonymous: true, EncryptedReporter: "secret", CreatedAt: time.Now()})
+	require.NoError(t, err)
+	_, err = s.Resolve(c.ID, "USTAFF", "Done", time.Now())
+	require.NoError(t, err)
+
+	c, err = s.Audit(c.ID, Event{Type: EventReporterRevealed, By: "USTAFF", Note: "Abuse follow-up", At: time.Now()})
+	require.NoError(t, err, "resolved cases must still be audited")
+	require.True(t, c.HasEvent(EventReporterRevealed))
+
+	_, err = s.Audit(2, Event{Type: EventReporterRevealed})
+	require.ErrorIs(t, err, ErrCaseNotFound)
+}
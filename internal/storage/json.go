@@ -0,0 +1,70 @@
+// Package storage provides minimal helpers to persist bot state as JSON files on the local disk.
+package storage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ReadJSON reads the JSON document at path into v.
+// A missing file is not an error; v is left untouched in that case.
+// An empty path is a no-op, which allows in-memory only usage.
+func ReadJSON(path string, v interface{}) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	if len(data) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(data, v); err != nil {
+		return fmt.Errorf("decode %q: %w", path, err)
+	}
+
+	return nil
+}
+
+// WriteJSON atomically writes v as JSON to path, creating parent directories if needed.
+// An empty path is a no-op, which allows in-memory only usage.
+func WriteJSON(path string, v interface{}) error {
+	if path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name()) // no-op once renamed
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), path)
+}
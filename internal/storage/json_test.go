@@ -0,0 +1,46 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type doc struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func TestReadWriteJSON(t *testing.T) {
+	t.Run("missing file leaves value untouched", func(t *testing.T) {
+		v := doc{Name: "untouched"}
+		require.NoError(t, ReadJSON(filepath.Join(t.TempDir(), "missing.json"), &v))
+		require.Equal(t, "untouched", v.Name)
+	})
+
+	t.Run("empty path is a no-op", func(t *testing.T) {
+		require.NoError(t, WriteJSON("", doc{Name: "foo"}))
+		require.NoError(t, ReadJSON("", &doc{}))
+	})
+
+	t.Run("round trip creating parent directories", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "nested", "dir", "doc.json")
+		require.NoError(t, WriteJSON(path, doc{Name: "foo", Count: 3}))
+
+		var v doc
+		require.NoError(t, ReadJSON(path, &v))
+		require.Equal(t, doc{Name: "foo", Count: 3}, v)
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		require.Len(t, entries, 1, "temporary files must be cleaned up")
+	})
+
+	t.Run("invalid content", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "doc.json")
+		require.NoError(t, os.WriteFile(path, []byte("{invalid"), 0o600))
+		require.Error(t, ReadJSON(path, &doc{}))
+	})
+}
@@ -6,10 +6,13 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
+	"github.com/alecthomas/kong"
 	"github.com/asaskevich/EventBus"
+	"github.com/bcneng/candebot/cmd"
 	"github.com/bcneng/candebot/handlers"
 	"github.com/slack-go/slack/slackevents"
 
@@ -34,6 +37,11 @@ func init() {
 }
 
 func main() {
+	if args := flag.Args(); len(args) > 0 {
+		runCLI(args)
+		return
+	}
+
 	var conf bot.Config
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -60,6 +68,45 @@ func main() {
 	}
 }
 
+// cliCommands are the commands that can run from the command line, as they need neither Slack nor the bot stores.
+var cliCommands = []string{"inclusion test"}
+
+// runCLI runs a bot command from the command line, e.g. `candebot inclusion test "hey guys"`. Only the config file is
+// read, so no Slack tokens are needed.
+func runCLI(args []string) {
+	var conf bot.Config
+	if err := bot.LoadConfigFromFile(initConf.ConfigFilePath, &conf); err != nil {
+		log.Fatal(err)
+	}
+
+	botCtx, err := bot.NewCLIContext(conf)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, kongCLI, err := cmd.NewCLI("candebot", args, kong.Writers(os.Stdout, os.Stderr))
+	if err != nil {
+		os.Exit(1)
+	}
+	if !isCLICommand(kongCLI.Command()) {
+		log.Fatalf("%q is not available from the command line, only %s", kongCLI.Command(), strings.Join(cliCommands, ", "))
+	}
+
+	if err := kongCLI.Run(botCtx, bot.SlackContext{}); err != nil {
+		log.Fatal(err)
+	}
+}
+
+func isCLICommand(command string) bool {
+	for _, c := range cliCommands {
+		if command == c || strings.HasPrefix(command, c+" ") {
+			return true
+		}
+	}
+
+	return false
+}
+
 func subscribe(bus EventBus.Bus, t slackevents.EventsAPIType, h bot.EventHandler) {
 	if err := bus.Subscribe(string(t), bot.CreateEventHandler(t, h)); err != nil {
 		log.Fatal(err)
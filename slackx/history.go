@@ -0,0 +1,92 @@
+package slackx
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/slack-go/slack"
+)
+
+// ErrMessageNotFound is returned when the requested message does not exist, or it was deleted.
+var ErrMessageNotFound = errors.New("message not found")
+
+// FetchMessage fetches a message along with up to contextSize messages around it, in chronological order.
+// Top level messages come with the messages posted right before them in the channel, while thread replies
+// come with the thread parent and the replies around them. The returned context includes the message itself.
+func FetchMessage(c *slack.Client, channelID, ts, threadTS string, contextSize int) (slack.Message, []slack.Message, error) {
+	if threadTS != "" && threadTS != ts {
+		return fetchThreadReply(c, channelID, ts, threadTS, contextSize)
+	}
+
+	history, err := c.GetConversationHistory(&slack.GetConversationHistoryParameters{
+		ChannelID: channelID,
+		Latest:    ts,
+		Inclusive: true,
+		Limit:     contextSize + 1,
+	})
+	if err != nil {
+		return slack.Message{}, nil, err
+	}
+
+	// Messages come newest first.
+	messages := make([]slack.Message, 0, len(history.Messages))
+	for i := len(history.Messages) - 1; i >= 0; i-- {
+		messages = append(messages, history.Messages[i])
+	}
+
+	if len(messages) == 0 || messages[len(messages)-1].Timestamp != ts {
+		return slack.Message{}, nil, ErrMessageNotFound
+	}
+
+	return messages[len(messages)-1], messages, nil
+}
+
+func fetchThreadReply(c *slack.Client, channelID, ts, threadTS string, contextSize int) (slack.Message, []slack.Message, error) {
+	params := &slack.GetConversationRepliesParameters{ChannelID: channelID, Timestamp: threadTS}
+
+	var replies []slack.Message
+	for {
+		page, more, cursor, err := c.GetConversationReplies(params)
+		if err != nil {
+			return slack.Message{}, nil, err
+		}
+		replies = append(replies, page...)
+
+		if !more || cursor == "" {
+			break
+		}
+		params.Cursor = cursor
+	}
+
+	for i, reply := range replies {
+		if reply.Timestamp != ts {
+			continue
+		}
+
+		from, to := i-contextSize/2, i+contextSize/2+1
+		if from < 1 {
+			from = 1
+		}
+		if to > len(replies) {
+			to = len(replies)
+		}
+
+		// The thread parent always comes first, as it gives sense to the replies.
+		context := append([]slack.Message{replies[0]}, replies[from:to]...)
+		return reply, context, nil
+	}
+
+	return slack.Message{}, nil, ErrMessageNotFound
+}
+
+// TimestampToTime converts a Slack message timestamp into time.
+func TimestampToTime(ts string) (time.Time, error) {
+	f, err := strconv.ParseFloat(ts, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), nil
+}
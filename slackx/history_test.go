@@ -0,0 +1,77 @@
+package slackx
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/slack-go/slack"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestSlackClient(t *testing.T, responses map[string]string) *slack.Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(responses[strings.TrimPrefix(r.URL.Path, "/")]))
+	}))
+	t.Cleanup(server.Close)
+
+	return slack.New("test-token", slack.OptionAPIURL(server.URL+"/"))
+}
+
+func TestFetchMessage(t *testing.T) {
+	c := newTestSlackClient(t, map[string]string{
+		"conversations.history": `{"ok": true, "messages": [
+			{"user": "UAUTHOR", "text": "reported", "ts": "3.0", "edited": {"user": "UAUTHOR", "ts": "4.0"}},
+			{"user": "UOTHER", "text": "before", "ts": "2.0"}
+		]}`,
+		"conversations.replies": `{"ok": true, "messages": [
+			{"user": "UPARENT", "text": "parent", "ts": "1.0", "thread_ts": "1.0"},
+			{"user": "U1", "text": "reply 1", "ts": "2.0", "thread_ts": "1.0"},
+			{"user": "U2", "text": "reply 2", "ts": "3.0", "thread_ts": "1.0"},
+			{"user": "UAUTHOR", "text": "reported reply", "ts": "4.0", "thread_ts": "1.0"},
+			{"user": "U3", "text": "reply 4", "ts": "5.0", "thread_ts": "1.0"},
+			{"user": "U4", "text": "reply 5", "ts": "6.0", "thread_ts": "1.0"}
+		]}`,
+	})
+
+	t.Run("top level message", func(t *testing.T) {
+		msg, context, err := FetchMessage(c, "C1", "3.0", "", 1)
+		require.NoError(t, err)
+		require.Equal(t, "reported", msg.Text)
+		require.Equal(t, "4.0", msg.Edited.Timestamp)
+		require.Len(t, context, 2)
+		require.Equal(t, "before", context[0].Text, "context must be chronological")
+	})
+
+	t.Run("thread reply", func(t *testing.T) {
+		msg, context, err := FetchMessage(c, "C1", "4.0", "1.0", 2)
+		require.NoError(t, err)
+		require.Equal(t, "reported reply", msg.Text)
+
+		var texts []string
+		for _, m := range context {
+			texts = append(texts, m.Text)
+		}
+		require.Equal(t, []string{"parent", "reply 2", "reported reply", "reply 4"}, texts)
+	})
+
+	t.Run("deleted message", func(t *testing.T) {
+		_, _, err := FetchMessage(c, "C1", "2.5", "", 1)
+		require.ErrorIs(t, err, ErrMessageNotFound)
+
+		_, _, err = FetchMessage(c, "C1", "9.0", "1.0", 1)
+		require.ErrorIs(t, err, ErrMessageNotFound)
+	})
+}
+
+func TestTimestampToTime(t *testing.T) {
+	ts, err := TimestampToTime("1700000000.123456")
+	require.NoError(t, err)
+	require.Equal(t, time.Unix(1700000000, 0).UTC(), ts.Truncate(time.Second).UTC())
+
+	_, err = TimestampToTime("invalid")
+	require.Error(t, err)
+}
@@ -0,0 +1,124 @@
+package slackx
+
+import "strings"
+
+// Kinds of segments of a message text.
+const (
+	SegmentProse   = "prose"   // Text written by the user.
+	SegmentCode    = "code"    // Code blocks and inline code.
+	SegmentQuote   = "quote"   // Quoted lines.
+	SegmentLink    = "link"    // URLs, with their label if any.
+	SegmentMention = "mention" // User, channel and special mentions like @here.
+)
+
+// Segment is a part of a message text, in Slack mrkdwn format.
+type Segment struct {
+	Kind  string
+	Text  string
+	Start int // Byte offset in the message text where the segment starts.
+	End   int // Byte offset in the message text where the segment ends.
+}
+
+// Tokenize splits a message text, as sent by Slack, into consecutive segments covering all of it.
+// Unclosed code blocks or inline code are considered prose, as Slack renders them.
+func Tokenize(text string) []Segment {
+	var segments []Segment
+	var proseStart int
+	add := func(kind string, start, end int) {
+		if proseStart < start {
+			segments = append(segments, Segment{Kind: SegmentProse, Text: text[proseStart:start], Start: proseStart, End: start})
+		}
+		if start < end {
+			segments = append(segments, Segment{Kind: kind, Text: text[start:end], Start: start, End: end})
+		}
+		proseStart = end
+	}
+
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		lineStart := i == 0 || text[i-1] == '\n'
+
+		switch {
+		case strings.HasPrefix(rest, "```"):
+			if end := strings.Index(rest[3:], "```"); end >= 0 {
+				add(SegmentCode, i, i+3+end+3)
+				i = proseStart
+				continue
+			}
+		case rest[0] == '`':
+			if end := strings.IndexAny(rest[1:], "`\n"); end > 0 && rest[1+end] == '`' {
+				add(SegmentCode, i, i+1+end+1)
+				i = proseStart
+				continue
+			}
+		case lineStart && (strings.HasPrefix(rest, "&gt;&gt;&gt;") || strings.HasPrefix(rest, ">>>")):
+			// Everything after is quoted
+			add(SegmentQuote, i, len(text))
+			i = proseStart
+			continue
+		case lineStart && (strings.HasPrefix(rest, "&gt;") || rest[0] == '>'):
+			end := strings.IndexByte(rest, '\n')
+			if end < 0 {
+				end = len(rest)
+			}
+			add(SegmentQuote, i, i+end)
+			i = proseStart
+			continue
+		case rest[0] == '<':
+			if end := strings.IndexAny(rest, ">\n"); end > 0 && rest[end] == '>' {
+				if kind := angleBracketsKind(rest[1:end]); kind != "" {
+					add(kind, i, i+end+1)
+					i = proseStart
+					continue
+				}
+			}
+		case (strings.HasPrefix(rest, "http://") || strings.HasPrefix(rest, "https://")) && (i == 0 || isSpace(text[i-1])):
+			end := strings.IndexAny(rest, " \n\t\r")
+			if end < 0 {
+				end = len(rest)
+			}
+			add(SegmentLink, i, i+end)
+			i = proseStart
+			continue
+		}
+
+		i++
+	}
+	add(SegmentProse, len(text), len(text)) // Flushes the trailing prose, if any.
+
+	return segments
+}
+
+// Prose returns the text with everything but prose replaced by spaces. Byte offsets are kept, so positions
+// found in the returned text are valid in the original one.
+func Prose(text string) string {
+	b := []byte(text)
+	for _, s := range Tokenize(text) {
+		if s.Kind == SegmentProse {
+			continue
+		}
+		for i := s.Start; i < s.End; i++ {
+			if b[i] != '\n' {
+				b[i] = ' '
+			}
+		}
+	}
+
+	return string(b)
+}
+
+// angleBracketsKind returns the kind of segment of a <...> Slack markup, or empty if it's not Slack markup.
+func angleBracketsKind(content string) string {
+	switch {
+	case strings.HasPrefix(content, "@"), strings.HasPrefix(content, "#"), strings.HasPrefix(content, "!"):
+		return SegmentMention
+	case strings.Contains(content, "://"), strings.HasPrefix(content, "mailto:"), strings.HasPrefix(content, "tel:"):
+		return SegmentLink
+	}
+
+	return ""
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t' || c == '\r'
+}
@@ -0,0 +1,64 @@
+package slackx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		segments []Segment
+	}{
+		{name: "empty", input: "", segments: nil},
+		{name: "only prose", input: "hello there", segments: []Segment{
+			{Kind: SegmentProse, Text: "hello there", Start: 0, End: 11},
+		}},
+		{name: "inline code", input: "run `kill -9` now", segments: []Segment{
+			{Kind: SegmentProse, Text: "run ", Start: 0, End: 4},
+			{Kind: SegmentCode, Text: "`kill -9`", Start: 4, End: 13},
+			{Kind: SegmentProse, Text: " now", Start: 13, End: 17},
+		}},
+		{name: "code block", input: "see:\n```\nmaster-slave\n```", segments: []Segment{
+			{Kind: SegmentProse, Text: "see:\n", Start: 0, End: 5},
+			{Kind: SegmentCode, Text: "```\nmaster-slave\n```", Start: 5, End: 25},
+		}},
+		{name: "unclosed code is prose", input: "a ` b", segments: []Segment{
+			{Kind: SegmentProse, Text: "a ` b", Start: 0, End: 5},
+		}},
+		{name: "quoted line", input: "&gt; hey guys\nthanks", segments: []Segment{
+			{Kind: SegmentQuote, Text: "&gt; hey guys", Start: 0, End: 13},
+			{Kind: SegmentProse, Text: "\nthanks", Start: 13, End: 20},
+		}},
+		{name: "quoted rest", input: "so\n&gt;&gt;&gt; a\nb", segments: []Segment{
+			{Kind: SegmentProse, Text: "so\n", Start: 0, End: 3},
+			{Kind: SegmentQuote, Text: "&gt;&gt;&gt; a\nb", Start: 3, End: 19},
+		}},
+		{name: "links and mentions", input: "<@U123> check <https://github.com/x/master-slave|PR> and https://a.com/b", segments: []Segment{
+			{Kind: SegmentMention, Text: "<@U123>", Start: 0, End: 7},
+			{Kind: SegmentProse, Text: " check ", Start: 7, End: 14},
+			{Kind: SegmentLink, Text: "<https://github.com/x/master-slave|PR>", Start: 14, End: 52},
+			{Kind: SegmentProse, Text: " and ", Start: 52, End: 57},
+			{Kind: SegmentLink, Text: "https://a.com/b", Start: 57, End: 72},
+		}},
+		{name: "angle brackets that are not markup", input: "a <b> c", segments: []Segment{
+			{Kind: SegmentProse, Text: "a <b> c", Start: 0, End: 7},
+		}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			require.Equal(t, test.segments, Tokenize(test.input))
+		})
+	}
+}
+
+func TestProse(t *testing.T) {
+	input := "hey `guys` <!here>\n&gt; crazy"
+	prose := Prose(input)
+	require.Len(t, prose, len(input))
+	require.Equal(t, "hey "+strings.Repeat(" ", len("`guys` <!here>"))+"\n"+strings.Repeat(" ", len("&gt; crazy")), prose)
+}
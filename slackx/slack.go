@@ -6,6 +6,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"regexp"
 	"strings"
 	"sync"
 
@@ -45,15 +46,21 @@ func SendEphemeral(c *slack.Client, threadTS, channelID, userID, msg string, opt
 }
 
 func Send(c *slack.Client, threadTS, channelID, msg string, scape bool, opts ...slack.MsgOption) error {
+	_, err := SendWithTimestamp(c, threadTS, channelID, msg, scape, opts...)
+	return err
+}
+
+// SendWithTimestamp sends a message like Send does, returning the timestamp of the posted message.
+func SendWithTimestamp(c *slack.Client, threadTS, channelID, msg string, scape bool, opts ...slack.MsgOption) (string, error) {
 	if channelID == "" {
-		return nil
+		return "", nil
 	}
-	_, _, err := c.PostMessage(channelID, append(opts, slack.MsgOptionText(msg, scape), slack.MsgOptionTS(threadTS))...)
+	_, ts, err := c.PostMessage(channelID, append(opts, slack.MsgOptionText(msg, scape), slack.MsgOptionTS(threadTS))...)
 	if err != nil {
 		log.Println("error sending msg in channel ", channelID)
 	}
 
-	return err
+	return ts, err
 }
 
 var publicPrivate = []string{"public_channel", "private_channel"}
@@ -152,3 +159,21 @@ func (r *ChannelResolver) findViaSlackAPI(channel string) (string, error) {
 func LinkToMessage(channelID, msgTimestamp string) string {
 	return fmt.Sprintf("https://bcneng.slack.com/archives/%s/p%s", channelID, strings.Replace(msgTimestamp, ".", "", 1))
 }
+
+var (
+	userIDRe      = regexp.MustCompile(`^[UW][A-Z0-9]+$`)
+	userMentionRe = regexp.MustCompile(`^<@([UW][A-Z0-9]+)(\|[^>]*)?>$`)
+)
+
+// ParseUserMention returns the user ID of a user mention like <@U123> or <@U123|name>. Plain user IDs are accepted too.
+func ParseUserMention(mention string) (string, bool) {
+	mention = strings.TrimSpace(mention)
+	if m := userMentionRe.FindStringSubmatch(mention); m != nil {
+		return m[1], true
+	}
+	if userIDRe.MatchString(mention) {
+		return mention, true
+	}
+
+	return "", false
+}
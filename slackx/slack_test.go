@@ -99,3 +99,27 @@ func TestFindChannelIDByName_ChannelNotInJSON(t *testing.T) {
 	_, err := resolver.FindChannelIDByName("missing-channel")
 	require.Error(t, err, "expected error from Slack API fallback")
 }
+
+func TestParseUserMention(t *testing.T) {
+	tests := []struct {
+		mention string
+		want    string
+		ok      bool
+	}{
+		{mention: "<@U123ABC>", want: "U123ABC", ok: true},
+		{mention: "<@U123ABC|john>", want: "U123ABC", ok: true},
+		{mention: "W123ABC", want: "W123ABC", ok: true},
+		{mention: "@john"},
+		{mention: "U123|john"},
+		{mention: "<#C123ABC>"},
+		{mention: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mention, func(t *testing.T) {
+			user, ok := ParseUserMention(tt.mention)
+			require.Equal(t, tt.ok, ok)
+			require.Equal(t, tt.want, user)
+		})
+	}
+}